@@ -0,0 +1,146 @@
+package zlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// PrintfLogger is the Printf-shaped logger accepted by many third-party
+// libraries, such as database drivers
+type PrintfLogger interface {
+	Printf(format string, args ...interface{})
+}
+
+// printfAdapter routes Printf calls into zlog at a fixed level
+type printfAdapter struct {
+	level    Level
+	zapLevel zapcore.Level
+}
+
+// PrintfAdapter returns a PrintfLogger that logs every Printf call at level
+// through the global logger
+func PrintfAdapter(level Level) PrintfLogger {
+	return printfAdapter{level: level, zapLevel: level.toZapCoreLevel()}
+}
+
+func (a printfAdapter) Printf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	executeHooks(a.level, msg, nil)
+	Logger().Log(a.zapLevel, msg)
+}
+
+// maxParsedLineBytes bounds how much of an unterminated line LevelParsingWriter buffers
+const maxParsedLineBytes = 64 * 1024
+
+var (
+	// levelPrefixPattern matches a level at the start of a line, as "[INFO]" or
+	// "INFO:". A bare word is not enough: "Error count: 0" is ordinary text.
+	levelPrefixPattern = regexp.MustCompile(`(?i)^\s*(?:\[(debug|info|warn|warning|error|err|fatal|panic)\]|(debug|info|warn|warning|error|err|fatal|panic):)\s*`)
+	// levelTagPattern matches a bracketed level anywhere in a line, e.g. after a timestamp
+	levelTagPattern = regexp.MustCompile(`(?i)\[(debug|info|warn|warning|error|err|fatal|panic)\]\s*`)
+)
+
+// levelParsingWriter logs each line written to it at the level named in the line
+type levelParsingWriter struct {
+	mu           sync.Mutex
+	defaultLevel Level
+	buf          []byte
+}
+
+// LevelParsingWriter returns a writer that logs every line written to it
+// through the global logger, for wrapping the output of a child process.
+// Close logs a last line that has no trailing newline:
+//
+//	w := zlog.LevelParsingWriter(zlog.InfoLevel)
+//	cmd.Stdout = w
+//	err := cmd.Run()
+//	w.Close()
+//
+// A level prefix such as "[ERROR]" or "warn:" at the start of a line, or a
+// bracketed level anywhere in it, selects the level and is removed from the
+// message; other lines are logged at defaultLevel. Fatal and panic
+// lines are logged at error level, so child output can never stop the process.
+func LevelParsingWriter(defaultLevel Level) io.WriteCloser {
+	if !defaultLevel.Valid() {
+		defaultLevel = InfoLevel
+	}
+	return &levelParsingWriter{defaultLevel: defaultLevel}
+}
+
+func (w *levelParsingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= maxParsedLineBytes {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// Close logs what is left of an unterminated last line. Writing after Close
+// is still allowed.
+func (w *levelParsingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *levelParsingWriter) logLine(line string) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	level, msg := w.defaultLevel, line
+	if m := levelPrefixPattern.FindStringSubmatchIndex(line); m != nil {
+		var name string
+		if m[2] >= 0 {
+			name = line[m[2]:m[3]]
+		} else {
+			name = line[m[4]:m[5]]
+		}
+		level, msg = parsedLevel(name, level), line[m[1]:]
+	} else if m := levelTagPattern.FindStringSubmatchIndex(line); m != nil {
+		level, msg = parsedLevel(line[m[2]:m[3]], level), line[:m[0]]+line[m[1]:]
+	}
+
+	executeHooks(level, msg, nil)
+	// Caller and stacktrace would only ever point at this writer
+	Logger().WithOptions(zap.WithCaller(false), zap.AddStacktrace(noLevels)).Log(level.toZapCoreLevel(), msg)
+}
+
+// noLevels enables no level
+var noLevels = zap.LevelEnablerFunc(func(zapcore.Level) bool { return false })
+
+// parsedLevel converts a level name found in child output, capping it at error
+func parsedLevel(name string, fallback Level) Level {
+	var level Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return fallback
+	}
+	if level == PanicLevel || level == FatalLevel {
+		return ErrorLevel
+	}
+	return level
+}
@@ -0,0 +1,54 @@
+package zlog
+
+import (
+	"io"
+	"testing"
+)
+
+func TestLevelParsingWriterLevels(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Level = DebugLevel
+	useGlobal(t, cfg)
+
+	w := LevelParsingWriter(InfoLevel)
+	_, _ = io.WriteString(w, "[ERROR] disk full\n"+
+		"warn: slow query\n"+
+		"2026-10-15 10:00:00 [debug] tick\n"+
+		"Error count: 0\n"+
+		"info only text\n")
+	_ = Sync()
+
+	want := []struct{ level, msg string }{
+		{"error", "disk full"},
+		{"warn", "slow query"},
+		{"debug", "2026-10-15 10:00:00 tick"},
+		{"info", "Error count: 0"},
+		{"info", "info only text"},
+	}
+	entries := readEntries(t, cfg.FilePath)
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		if entries[i]["level"] != w.level || entries[i]["msg"] != w.msg {
+			t.Errorf("entry %d = %v %q, want %s %q", i, entries[i]["level"], entries[i]["msg"], w.level, w.msg)
+		}
+	}
+}
+
+func TestLevelParsingWriterCloseLogsLastLine(t *testing.T) {
+	cfg := testConfig(t)
+	useGlobal(t, cfg)
+
+	w := LevelParsingWriter(InfoLevel)
+	_, _ = io.WriteString(w, "first\nno trailing newline")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = Sync()
+
+	entries := readEntries(t, cfg.FilePath)
+	if len(entries) != 2 || entries[1]["msg"] != "no trailing newline" {
+		t.Fatalf("got %v, want the unterminated line logged", entries)
+	}
+}
@@ -0,0 +1,43 @@
+package zlog
+
+import (
+	"strconv"
+	"testing"
+)
+
+const benchBatchSize = 100
+
+func benchBatch() []BatchEntry {
+	entries := make([]BatchEntry, benchBatchSize)
+	for i := range entries {
+		entries[i] = BatchEntry{Msg: "row imported", Fields: []Field{String("id", strconv.Itoa(i)), Int("row", i)}}
+	}
+	return entries
+}
+
+// benchBatchConfig buffers file writes so they do not hide the per-call cost
+func benchBatchConfig(b *testing.B) LoggerConfig {
+	cfg := testConfig(b)
+	cfg.BufferSize = 256 * 1024
+	return cfg
+}
+
+func BenchmarkLogBatch(b *testing.B) {
+	useGlobal(b, benchBatchConfig(b))
+	entries := benchBatch()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		LogBatch(InfoLevel, entries)
+	}
+}
+
+func BenchmarkLogIndividually(b *testing.B) {
+	useGlobal(b, benchBatchConfig(b))
+	entries := benchBatch()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, e := range entries {
+			Info(e.Msg, e.Fields...)
+		}
+	}
+}
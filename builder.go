@@ -0,0 +1,84 @@
+package zlog
+
+import "time"
+
+// EntryBuilder collects fields through chained calls and logs them with a
+// terminal method such as Info. It is sugar over the field constructors:
+//
+//	zlog.NewEntry().Str("user", name).Int("attempts", n).Err(err).Error("login failed")
+//
+// A builder is meant for a single entry and is not safe for concurrent use.
+type EntryBuilder struct {
+	fields []Field
+}
+
+// NewEntry returns an empty EntryBuilder
+func NewEntry() *EntryBuilder {
+	return &EntryBuilder{fields: make([]Field, 0, 8)}
+}
+
+func (b *EntryBuilder) Str(key, val string) *EntryBuilder {
+	b.fields = append(b.fields, String(key, val))
+	return b
+}
+func (b *EntryBuilder) Int(key string, val int) *EntryBuilder {
+	b.fields = append(b.fields, Int(key, val))
+	return b
+}
+func (b *EntryBuilder) Int64(key string, val int64) *EntryBuilder {
+	b.fields = append(b.fields, Int64(key, val))
+	return b
+}
+func (b *EntryBuilder) Bool(key string, val bool) *EntryBuilder {
+	b.fields = append(b.fields, Bool(key, val))
+	return b
+}
+func (b *EntryBuilder) Float64(key string, val float64) *EntryBuilder {
+	b.fields = append(b.fields, Float64(key, val))
+	return b
+}
+func (b *EntryBuilder) Dur(key string, val time.Duration) *EntryBuilder {
+	b.fields = append(b.fields, Duration(key, val))
+	return b
+}
+func (b *EntryBuilder) Time(key string, val time.Time) *EntryBuilder {
+	b.fields = append(b.fields, Time(key, val))
+	return b
+}
+func (b *EntryBuilder) Any(key string, val interface{}) *EntryBuilder {
+	b.fields = append(b.fields, Any(key, val))
+	return b
+}
+func (b *EntryBuilder) Err(err error) *EntryBuilder {
+	b.fields = append(b.fields, Err(err))
+	return b
+}
+
+// Field adds fields built by any other constructor
+func (b *EntryBuilder) Field(fields ...Field) *EntryBuilder {
+	b.fields = append(b.fields, fields...)
+	return b
+}
+
+// Fields returns the collected fields
+func (b *EntryBuilder) Fields() []Field {
+	return b.fields
+}
+
+// Terminal methods: log the collected fields with the global logger
+func (b *EntryBuilder) Debug(msg string) {
+	executeHooks(DebugLevel, msg, b.fields)
+	Logger().Debug(msg, b.fields...)
+}
+func (b *EntryBuilder) Info(msg string) {
+	executeHooks(InfoLevel, msg, b.fields)
+	Logger().Info(msg, b.fields...)
+}
+func (b *EntryBuilder) Warn(msg string) {
+	executeHooks(WarnLevel, msg, b.fields)
+	Logger().Warn(msg, b.fields...)
+}
+func (b *EntryBuilder) Error(msg string) {
+	executeHooks(ErrorLevel, msg, b.fields)
+	Logger().Error(msg, b.fields...)
+}
@@ -0,0 +1,58 @@
+package zlog
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// CaptureToBuffer redirects the stdout/stderr outputs of the global logger
+// to a buffer, keeping their encoders, and returns the buffer with a function
+// that restores the original outputs. File and network outputs are not
+// affected. Read the buffer only after calling restore, or from the logging
+// goroutine, since writes to it are not synchronized with readers.
+//
+//	buf, restore := zlog.CaptureToBuffer()
+//	doWork()
+//	restore()
+//	got := zlog.StripTimestamps(buf.Bytes())
+func CaptureToBuffer() (*bytes.Buffer, func()) {
+	state := currentState()
+	buf := &bytes.Buffer{}
+	ws := zapcore.Lock(zapcore.AddSync(buf))
+
+	var prev []zapcore.WriteSyncer
+	for _, sw := range state.consoles {
+		// The encoder was built before, so rebuilding cannot fail in practice;
+		// a nil entry just leaves that output alone on restore
+		old, _ := sw.setWriter(ws)
+		prev = append(prev, old)
+	}
+
+	var once sync.Once
+	restore := func() {
+		once.Do(func() {
+			for i, sw := range state.consoles {
+				if prev[i] != nil {
+					_, _ = sw.setWriter(prev[i])
+				}
+			}
+		})
+	}
+	return buf, restore
+}
+
+var (
+	jsonTimestampPattern    = regexp.MustCompile(`"ts":"[^"]*"`)
+	consoleTimestampPattern = regexp.MustCompile(`(?m)^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}(:?\d{2})?)?\t`)
+)
+
+// StripTimestamps replaces the ts field of JSON lines and the leading
+// timestamp of console lines with a fixed placeholder, so captured output can
+// be compared with a golden file
+func StripTimestamps(b []byte) []byte {
+	b = jsonTimestampPattern.ReplaceAll(b, []byte(`"ts":"<ts>"`))
+	return consoleTimestampPattern.ReplaceAll(b, []byte("<ts>\t"))
+}
@@ -0,0 +1,52 @@
+package zlog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCaptureToBufferRestores(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Output = "both"
+	useGlobal(t, cfg)
+
+	buf, restore := CaptureToBuffer()
+	Info("captured")
+	restore()
+	restore() // restoring again is a no-op
+
+	// Capture again so the next entry does not reach stdout
+	second, restoreSecond := CaptureToBuffer()
+	Info("not captured")
+	restoreSecond()
+
+	got := buf.String()
+	if !strings.Contains(got, `"msg":"captured"`) {
+		t.Errorf("buffer is missing the captured entry: %q", got)
+	}
+	if strings.Contains(got, "not captured") {
+		t.Errorf("buffer got an entry written after restore: %q", got)
+	}
+	if !strings.Contains(second.String(), "not captured") {
+		t.Errorf("second buffer is missing its entry: %q", second)
+	}
+	// The file still sees both
+	if n := countLines(t, cfg.FilePath); n != 2 {
+		t.Errorf("file has %d lines, want 2", n)
+	}
+}
+
+func TestStripTimestamps(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{`{"level":"info","ts":"2024-05-01T10:00:00.123+0800","msg":"hi"}`, `{"level":"info","ts":"<ts>","msg":"hi"}`},
+		{"2024-05-01T10:00:00.123+0800\tINFO\thi", "<ts>\tINFO\thi"},
+		{"2024-05-01T10:00:00Z\tINFO\thi\n2024-05-01T10:00:01Z\tWARN\tho", "<ts>\tINFO\thi\n<ts>\tWARN\tho"},
+		{"INFO\tat 2024-05-01T10:00:00Z\t", "INFO\tat 2024-05-01T10:00:00Z\t"}, // only at the start of a line
+	} {
+		if got := string(StripTimestamps([]byte(tt.in))); got != tt.want {
+			t.Errorf("StripTimestamps(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
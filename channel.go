@@ -0,0 +1,64 @@
+package zlog
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ChannelHook delivers written entries at or above a level to a channel,
+// e.g. to feed an in-process alerting routine. It is created by
+// NewChannelHook.
+type ChannelHook struct {
+	minLevel zapcore.Level
+	ch       chan HookEntry
+	dropped  atomic.Uint64
+}
+
+// NewChannelHook returns a hook sending every written entry at or above
+// minLevel to the returned channel, which buffers up to buffer entries.
+// Register it with RegisterLogHook. Entries are never waited for: when the
+// channel is full the entry is dropped and counted, so the consumer must
+// drain the channel promptly. The hook is a *ChannelHook, whose Dropped
+// method returns the count:
+//
+//	hook, alerts := zlog.NewChannelHook(zlog.ErrorLevel, 100)
+//	zlog.RegisterLogHook(hook)
+//	go func() {
+//		for e := range alerts {
+//			page(e.Message)
+//		}
+//	}()
+func NewChannelHook(minLevel Level, buffer int) (LogHook, <-chan HookEntry) {
+	if !minLevel.Valid() {
+		minLevel = ErrorLevel
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+	h := &ChannelHook{minLevel: minLevel.toZapCoreLevel(), ch: make(chan HookEntry, buffer)}
+	return h, h.ch
+}
+
+// OnLog is not called for a registered ChannelHook, which receives OnEntry instead
+func (h *ChannelHook) OnLog(level Level, msg string, fields []Field) error {
+	return nil
+}
+
+func (h *ChannelHook) OnEntry(e HookEntry) {
+	if e.Level.toZapCoreLevel() < h.minLevel {
+		return
+	}
+	// The consumer reads the entry later, after the caller may have reused the slice
+	e.Fields = append([]Field(nil), e.Fields...)
+	select {
+	case h.ch <- e:
+	default:
+		h.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many entries were dropped because the channel was full
+func (h *ChannelHook) Dropped() uint64 {
+	return h.dropped.Load()
+}
@@ -0,0 +1,30 @@
+package zlog
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// CheckedEntry is an entry that has already passed the level check.
+// It shares zap's representation, so Check does not allocate a wrapper.
+type CheckedEntry zapcore.CheckedEntry
+
+// Check returns a CheckedEntry if logging msg at level is enabled, or nil
+// otherwise, so fields are only built for entries that will be written:
+//
+//	if ce := zlog.Check(zlog.DebugLevel, "cache miss"); ce != nil {
+//		ce.Write(zlog.String("key", expensiveKey()))
+//	}
+func Check(level Level, msg string) *CheckedEntry {
+	return (*CheckedEntry)(Logger().Check(level.toZapCoreLevel(), msg))
+}
+
+// Write logs the entry with fields, which are normalized by toZapFields like
+// any other entry. Calling Write on a nil CheckedEntry is a no-op.
+func (c *CheckedEntry) Write(fields ...Field) {
+	if c == nil {
+		return
+	}
+	ce := (*zapcore.CheckedEntry)(c)
+	executeHooks(fromZapCoreLevel(ce.Level), ce.Message, fields)
+	ce.Write(fields...)
+}
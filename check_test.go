@@ -0,0 +1,25 @@
+package zlog
+
+import (
+	"testing"
+	"time"
+)
+
+// Debug below the configured level still builds its fields
+func BenchmarkDebugWithFields(b *testing.B) {
+	useGlobal(b, testConfig(b))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Debug("cache miss", String("key", "user:42"), Int("shard", 3), Duration("age", time.Second))
+	}
+}
+
+func BenchmarkCheck(b *testing.B) {
+	useGlobal(b, testConfig(b))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if ce := Check(DebugLevel, "cache miss"); ce != nil {
+			ce.Write(String("key", "user:42"), Int("shard", 3), Duration("age", time.Second))
+		}
+	}
+}
@@ -2,19 +2,61 @@ package zlog
 
 import (
 	"fmt"
+	"os"
+	"time"
 )
 
 type LoggerConfig struct {
-	Level      Level             `yaml:"level"`
-	Output     string            `yaml:"output"` // file、console、both
-	Format     string            `yaml:"format"` // json、console
-	FilePath   string            `yaml:"file_path"`
-	MaxSize    int               `yaml:"max_size"`
-	MaxBackups int               `yaml:"max_backups"`
-	MaxAge     int               `yaml:"max_age"`
-	Compress   bool              `yaml:"compress"`
-	Sampling   bool              `yaml:"sampling"`
-	Fields     map[string]string `yaml:"fields"` // 添加固定键值对
+	Name                string                 `yaml:"name"`           // written as the logger field of every entry
+	MessagePrefix       string                 `yaml:"message_prefix"` // e.g. cache logs every message as "[cache] message"
+	Strict              bool                   `yaml:"strict"`         // reject unknown Level, Output, Format and ConsoleStream values instead of falling back to defaults
+	Level               Level                  `yaml:"level"`
+	ConsoleLevel        Level                  `yaml:"console_level"`    // overrides Level for console output when set
+	FileLevel           Level                  `yaml:"file_level"`       // overrides Level for file output when set
+	Output              string                 `yaml:"output"`           // file、console、both、eventlog (Windows only)
+	Sinks               []SinkConfig           `yaml:"sinks"`            // explicit destinations; replaces Output when set
+	OutputsByLevel      map[Level]WriterConfig `yaml:"outputs_by_level"` // sends each listed level only to its own destination; other levels use Sinks or Output
+	Format              string                 `yaml:"format"`           // json、json-pretty (console only)、console、gelf, or a RegisterEncoder name
+	ConsoleFormat       string                 `yaml:"console_format"`   // overrides Format for console output when set
+	FileFormat          string                 `yaml:"file_format"`      // overrides Format for file output when set, e.g. console for a human-readable file next to json on stdout
+	ConsoleStream       string                 `yaml:"console_stream"`   // stdout、stderr
+	EventLogSource      string                 `yaml:"event_log_source"` // Windows Event Log source for Output eventlog; defaults to zlog
+	LevelColors         map[Level]string       `yaml:"level_colors"`     // per-level colors for colored console output, e.g. warn: magenta or "1;33"
+	LevelEncoder        string                 `yaml:"level_encoder"`    // lowercase、capital、capitalColor、lowercaseColor for every output; defaults to colored console and lowercase elsewhere
+	CallerEncoder       string                 `yaml:"caller_encoder"`   // short (pkg/file.go:12)、full、base (file.go:12); defaults to short
+	FilePath            string                 `yaml:"file_path"`
+	Rotate              *bool                  `yaml:"rotate"`             // false appends without rotating, for logrotate; call HandleReopenSignal to reopen on SIGHUP; defaults to true
+	FilenameTemplate    string                 `yaml:"filename_template"`  // e.g. app-{date}.log in FilePath's directory; tokens: {date} {hour} {pid} {hostname}
+	RotateLocation      string                 `yaml:"rotate_location"`    // IANA time zone for FilenameTemplate dates, e.g. America/New_York; defaults to local time
+	BackupTimeFormat    string                 `yaml:"backup_time_format"` // Go time layout in rotated file names; defaults to 2006-01-02T15-04-05.000
+	BackupSeparator     string                 `yaml:"backup_separator"`   // between the file name and the time in rotated file names; defaults to -
+	MaxSize             int                    `yaml:"max_size"`
+	MaxBackups          int                    `yaml:"max_backups"`
+	MaxAge              int                    `yaml:"max_age"`
+	Compress            bool                   `yaml:"compress"`
+	BufferSize          int                    `yaml:"buffer_size"`           // bytes buffered before writing to the file; 0 disables buffering
+	FlushInterval       time.Duration          `yaml:"flush_interval"`        // when set, outputs are synced this often until Close; the file buffer defaults to 30s
+	FileErrorFallback   bool                   `yaml:"file_error_fallback"`   // switch file output to stderr after repeated write failures
+	Sampling            bool                   `yaml:"sampling"`              // each logger built by New or InitLogger samples on its own counters
+	SamplingPerKey      bool                   `yaml:"sampling_per_key"`      // sample each level+message on its own counter
+	SamplingLevelFloor  Level                  `yaml:"sampling_level_floor"`  // levels at or above this are never sampled; defaults to error
+	SamplingLevels      []Level                `yaml:"sampling_levels"`       // when set, only these levels are sampled and SamplingLevelFloor is ignored
+	Fields              map[string]string      `yaml:"fields"`                // 添加固定键值对
+	DurationFormat      string                 `yaml:"duration_format"`       // seconds、millis、nanos、string
+	StacktraceAsArray   bool                   `yaml:"stacktrace_as_array"`   // emit stacktrace as one array element per frame
+	StacktraceLevel     Level                  `yaml:"stacktrace_level"`      // entries at or above this get a stacktrace; defaults to error
+	SanitizeMessages    bool                   `yaml:"sanitize_messages"`     // escape control characters in messages and string fields
+	IncludeHostname     bool                   `yaml:"include_hostname"`      // add the host name to every entry
+	HostnameKey         string                 `yaml:"hostname_key"`          // field name for the host name; defaults to hostname
+	IncludePID          bool                   `yaml:"include_pid"`           // add the process ID to every entry
+	IncludeUptime       bool                   `yaml:"include_uptime"`        // add process_start and a per-entry process_uptime
+	IncludeGoroutineID  bool                   `yaml:"include_goroutine_id"`  // add the logging goroutine's ID; costs a stack dump per entry, for debugging only
+	SplitCaller         bool                   `yaml:"split_caller"`          // log caller_file and caller_line instead of caller
+	IncludeFunction     bool                   `yaml:"include_function"`      // add the calling function (caller_function with SplitCaller)
+	MaxFieldBytes       int                    `yaml:"max_field_bytes"`       // truncate longer string fields; 0 means no limit
+	TruncateMessage     bool                   `yaml:"truncate_message"`      // apply MaxFieldBytes to the message as well
+	IncludeContextError bool                   `yaml:"include_context_error"` // *Ctx functions add ctx_err when the context is done
+	WarnRateThreshold   int                    `yaml:"warn_rate_threshold"`   // log one warning when more entries than this are written in a second; 0 disables
 }
 
 func (c *LoggerConfig) Validate() error {
@@ -27,23 +69,207 @@ func (c *LoggerConfig) Validate() error {
 	if c.MaxAge < 0 {
 		c.MaxAge = 30
 	}
-	if (c.Output == "file" || c.Output == "both") && c.FilePath == "" {
+	if c.Strict {
+		if err := c.validateStrict(); err != nil {
+			return err
+		}
+	}
+	if c.ConsoleLevel != "" && !c.ConsoleLevel.Valid() {
+		return fmt.Errorf("invalid ConsoleLevel: %q", string(c.ConsoleLevel))
+	}
+	if c.FileLevel != "" && !c.FileLevel.Valid() {
+		return fmt.Errorf("invalid FileLevel: %q", string(c.FileLevel))
+	}
+	if len(c.Sinks) == 0 && (c.Output == "file" || c.Output == "both") && c.FilePath == "" {
 		return fmt.Errorf("FilePath is required when Output='file'")
 	}
+	if c.FilePath != "" {
+		if info, err := os.Stat(c.FilePath); err == nil && info.IsDir() {
+			return fmt.Errorf("FilePath %q is a directory", c.FilePath)
+		}
+	}
+	if c.LevelEncoder != "" && !levelEncoders[c.LevelEncoder] {
+		return fmt.Errorf("unknown LevelEncoder: %q", c.LevelEncoder)
+	}
+	switch c.CallerEncoder {
+	case "", "short", "full", "base":
+	default:
+		return fmt.Errorf("unknown CallerEncoder: %q", c.CallerEncoder)
+	}
+	for lvl, color := range c.LevelColors {
+		if !lvl.Valid() {
+			return fmt.Errorf("LevelColors: %w", errInvalidLevel(lvl))
+		}
+		if _, err := ansiColorCode(color); err != nil {
+			return fmt.Errorf("LevelColors[%s]: %w", lvl, err)
+		}
+	}
+	for _, lvl := range c.SamplingLevels {
+		if !lvl.Valid() {
+			return fmt.Errorf("SamplingLevels: %w", errInvalidLevel(lvl))
+		}
+	}
+	for i, s := range c.Sinks {
+		if s.Destination == "" {
+			return fmt.Errorf("Sinks[%d]: Destination is required", i)
+		}
+		if s.Level != "" && !s.Level.Valid() {
+			return fmt.Errorf("Sinks[%d]: invalid Level: %q", i, string(s.Level))
+		}
+		if s.Route.Field != "" && (isConsoleDestination(s.Destination) || isNetworkDestination(s.Destination)) {
+			return fmt.Errorf("Sinks[%d]: Route requires a file destination", i)
+		}
+		if isNetworkDestination(s.Destination) {
+			if _, _, err := parseNetworkDestination(s.Destination); err != nil {
+				return fmt.Errorf("Sinks[%d]: %w", i, err)
+			}
+			if l := s.Network.CompressLevel; l < 0 || l > 9 {
+				return fmt.Errorf("Sinks[%d]: invalid network CompressLevel %d: must be between 1 and 9", i, l)
+			}
+		}
+	}
+	if err := validateOutputsByLevel(c.OutputsByLevel); err != nil {
+		return err
+	}
+	if c.FilenameTemplate != "" {
+		if err := validateFilenameTemplate(c.FilenameTemplate); err != nil {
+			return err
+		}
+	}
+	if err := validateBackupNaming(c.BackupTimeFormat, c.BackupSeparator); err != nil {
+		return err
+	}
+	if _, err := rotateLocation(c.RotateLocation); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateStrict rejects the values newLogger would otherwise silently
+// replace; empty values still mean the default. Unknown formats, including
+// those of Sinks and OutputsByLevel, fall back to console without Strict.
+func (c *LoggerConfig) validateStrict() error {
+	if c.Level != "" && !c.Level.Valid() {
+		return fmt.Errorf("invalid Level: %q", string(c.Level))
+	}
+	switch c.Output {
+	case "", "console", "file", "both", "eventlog":
+	default:
+		return fmt.Errorf("unknown Output: %q (want console, file, both or eventlog)", c.Output)
+	}
+	if c.ConsoleStream != "" && c.ConsoleStream != "stdout" && c.ConsoleStream != "stderr" {
+		return fmt.Errorf("unknown ConsoleStream: %q (want stdout or stderr)", c.ConsoleStream)
+	}
+	if c.Format != "" {
+		if _, ok := lookupEncoder(c.Format); !ok {
+			return fmt.Errorf("unknown Format: %q", c.Format)
+		}
+	}
+	if c.ConsoleFormat != "" {
+		if _, ok := lookupEncoder(c.ConsoleFormat); !ok {
+			return fmt.Errorf("unknown ConsoleFormat: %q", c.ConsoleFormat)
+		}
+	}
+	if c.FileFormat != "" {
+		if _, ok := lookupEncoder(c.FileFormat); !ok {
+			return fmt.Errorf("unknown FileFormat: %q", c.FileFormat)
+		}
+	}
+	for i, s := range c.Sinks {
+		if s.Format != "" {
+			if _, ok := lookupEncoder(s.Format); !ok {
+				return fmt.Errorf("Sinks[%d]: unknown Format: %q", i, s.Format)
+			}
+		}
+	}
+	for lvl, w := range c.OutputsByLevel {
+		if w.Format != "" {
+			if _, ok := lookupEncoder(w.Format); !ok {
+				return fmt.Errorf("OutputsByLevel[%s]: unknown Format: %q", lvl, w.Format)
+			}
+		}
+	}
 	return nil
 }
 
 func DefaultConfig() LoggerConfig {
 	return LoggerConfig{
-		Level:      InfoLevel,
-		Output:     "console",
-		Format:     "console",
-		FilePath:   "",
-		MaxSize:    100, // MB
-		MaxBackups: 10,
-		MaxAge:     30, // days
-		Compress:   true,
-		Sampling:   false,
-		Fields:     map[string]string{}, // 添加固定键值对
+		Strict:              false,
+		MessagePrefix:       "",
+		Level:               InfoLevel,
+		Output:              "console",
+		Format:              "console",
+		OutputsByLevel:      nil,
+		ConsoleFormat:       "",
+		FileFormat:          "",
+		ConsoleStream:       "stdout",
+		LevelEncoder:        "",
+		CallerEncoder:       "short",
+		FilePath:            "",
+		Rotate:              nil, // rotate
+		FilenameTemplate:    "",
+		RotateLocation:      "",
+		BackupTimeFormat:    "",
+		BackupSeparator:     "",
+		MaxSize:             100, // MB
+		MaxBackups:          10,
+		MaxAge:              30, // days
+		Compress:            true,
+		BufferSize:          0,
+		FlushInterval:       0,
+		FileErrorFallback:   false,
+		Sampling:            false,
+		SamplingPerKey:      false,
+		SamplingLevelFloor:  ErrorLevel,
+		SamplingLevels:      nil,
+		Fields:              map[string]string{}, // 添加固定键值对
+		DurationFormat:      "seconds",
+		StacktraceAsArray:   false,
+		StacktraceLevel:     ErrorLevel,
+		SanitizeMessages:    false,
+		IncludeHostname:     false,
+		HostnameKey:         "hostname",
+		IncludePID:          false,
+		IncludeUptime:       false,
+		IncludeGoroutineID:  false,
+		SplitCaller:         false,
+		IncludeFunction:     false,
+		MaxFieldBytes:       0,
+		TruncateMessage:     false,
+		IncludeContextError: false,
+		WarnRateThreshold:   0,
 	}
 }
+
+// DevelopmentConfig returns a configuration for local runs: debug level,
+// colored console output, stacktraces from warn up and no file output.
+func DevelopmentConfig() LoggerConfig {
+	cfg := DefaultConfig()
+	cfg.Level = DebugLevel
+	cfg.Output = "console"
+	cfg.Format = "console"
+	cfg.StacktraceLevel = WarnLevel
+	return cfg
+}
+
+// ProductionConfig returns the recommended settings for production services:
+//   - Level info, StacktraceLevel error
+//   - Output both: JSON to stdout and to ./logs/app.log
+//   - rotation at 100 MB, keeping 10 compressed backups for 30 days
+//   - Sampling on (first 100 then every 100th identical message per second,
+//     error and above never sampled)
+func ProductionConfig() LoggerConfig {
+	cfg := DefaultConfig()
+	cfg.Level = InfoLevel
+	cfg.Output = "both"
+	cfg.Format = "json"
+	cfg.FilePath = "./logs/app.log"
+	cfg.MaxSize = 100
+	cfg.MaxBackups = 10
+	cfg.MaxAge = 30
+	cfg.Compress = true
+	cfg.Sampling = true
+	cfg.SamplingLevelFloor = ErrorLevel
+	cfg.StacktraceLevel = ErrorLevel
+	return cfg
+}
@@ -0,0 +1,34 @@
+package zlog
+
+import "testing"
+
+func TestValidateUnknownFormat(t *testing.T) {
+	for _, set := range []func(*LoggerConfig){
+		func(c *LoggerConfig) { c.Format = "bogus" },
+		func(c *LoggerConfig) { c.ConsoleFormat = "bogus" },
+		func(c *LoggerConfig) { c.FileFormat = "bogus" },
+		func(c *LoggerConfig) { c.Sinks = []SinkConfig{{Destination: "stdout", Format: "bogus"}} },
+		func(c *LoggerConfig) {
+			c.OutputsByLevel = map[Level]WriterConfig{ErrorLevel: {Destination: "stderr", Format: "bogus"}}
+		},
+	} {
+		cfg := DefaultConfig()
+		set(&cfg)
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("non-strict Validate: %v, want fallback to console", err)
+		}
+		cfg.Strict = true
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("strict Validate accepted an unknown format")
+		}
+	}
+}
+
+func TestNewRejectsUnknownRotateLocation(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.RotateLocation = "Mars/Olympus_Mons"
+	if l, err := New(cfg); err == nil {
+		l.Close()
+		t.Fatal("New accepted an unknown RotateLocation")
+	}
+}
@@ -0,0 +1,73 @@
+package zlog
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// contractRule requires fields on messages starting with prefix
+type contractRule struct {
+	prefix   string
+	required []string
+}
+
+// contractHook warns about entries that break a field contract
+type contractHook struct {
+	rules []contractRule
+}
+
+// NewContractHook returns a LogHook that checks field contracts: rules maps
+// a message prefix to the field keys every message with that prefix must
+// carry. Violations are reported on stderr, so running tests against it
+// catches log calls that forget a required field:
+//
+//	zlog.RegisterLogHook(zlog.NewContractHook(map[string][]string{
+//		"audit:": {"actor", "action"},
+//	}))
+//
+// Only structured fields are checked; the key-value pairs of the *w
+// functions are not visible to hooks.
+func NewContractHook(rules map[string][]string) LogHook {
+	h := &contractHook{}
+	for prefix, required := range rules {
+		if len(required) == 0 {
+			continue
+		}
+		h.rules = append(h.rules, contractRule{prefix: prefix, required: append([]string(nil), required...)})
+	}
+	sort.Slice(h.rules, func(i, j int) bool { return h.rules[i].prefix < h.rules[j].prefix })
+	return h
+}
+
+func (h *contractHook) OnLog(level Level, msg string, fields []Field) error {
+	for _, rule := range h.rules {
+		if !strings.HasPrefix(msg, rule.prefix) {
+			continue
+		}
+		if missing := missingFields(rule.required, fields); len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "[zlog] contract violation: %s message %q is missing required fields %s\n",
+				level, msg, strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// missingFields returns the keys in required that no field carries
+func missingFields(required []string, fields []Field) []string {
+	var missing []string
+	for _, key := range required {
+		found := false
+		for _, f := range fields {
+			if f.Key == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
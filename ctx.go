@@ -2,130 +2,224 @@ package zlog
 
 import (
 	"context"
+	"fmt"
 
 	"go.uber.org/zap"
 )
 
+// ctxKey is the type of zlog's context keys. Use the ContextWith* helpers
+// to set them; values stored under the plain strings "request_id", "user_id"
+// and "trace_id" by other libraries are picked up as well.
 type ctxKey string
 
+// fieldsCtxKey is the context key of the fields added by ContextWithFields
+type fieldsCtxKey struct{}
+
 const (
 	RequestIDKey ctxKey = "request_id"
 	UserIDKey    ctxKey = "user_id"
 	TraceIDKey   ctxKey = "trace_id"
 )
 
-func loggerWithContext(ctx context.Context) *zap.Logger {
-	logger := Logger()
+// ContextWithRequestID returns a copy of ctx carrying the request ID logged
+// as request_id by the *Ctx functions
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, id)
+}
 
-	var extraFields []zap.Field
+// ContextWithUserID returns a copy of ctx carrying the user ID logged as user_id
+func ContextWithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, UserIDKey, id)
+}
 
-	if reqID, ok := ctx.Value(RequestIDKey).(string); ok && reqID != "" {
-		extraFields = append(extraFields, zap.String("request_id", reqID))
+// ContextWithTraceID returns a copy of ctx carrying the trace ID logged as trace_id
+func ContextWithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, TraceIDKey, id)
+}
+
+// contextString returns the string stored under key, falling back to the raw
+// string key used by libraries that do not know zlog's key type
+func contextString(ctx context.Context, key ctxKey) string {
+	if v, ok := ctx.Value(key).(string); ok && v != "" {
+		return v
 	}
-	if userID, ok := ctx.Value(UserIDKey).(string); ok && userID != "" {
-		extraFields = append(extraFields, zap.String("user_id", userID))
+	v, _ := ctx.Value(string(key)).(string)
+	return v
+}
+
+// ContextWithFields returns a copy of ctx carrying fields, which the *Ctx
+// functions and FromContext add to every entry logged with it or a context
+// derived from it. Fields accumulate across nested calls.
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
 	}
-	if traceID, ok := ctx.Value(TraceIDKey).(string); ok && traceID != "" {
-		extraFields = append(extraFields, zap.String("trace_id", traceID))
+	parent := contextFields(ctx)
+	all := make([]Field, 0, len(parent)+len(fields))
+	all = append(all, parent...)
+	all = append(all, fields...)
+	return context.WithValue(ctx, fieldsCtxKey{}, all)
+}
+
+// FieldsFromContext returns a copy of the fields added to ctx with
+// ContextWithFields, oldest first, or nil if there are none
+func FieldsFromContext(ctx context.Context) []Field {
+	fields := contextFields(ctx)
+	if len(fields) == 0 {
+		return nil
 	}
+	return append([]Field(nil), fields...)
+}
 
-	if len(extraFields) > 0 {
-		logger = logger.With(extraFields...)
-	}
-	return logger
+// contextFields returns the fields stored by ContextWithFields
+func contextFields(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsCtxKey{}).([]Field)
+	return fields
 }
 
-func sugarWithContext(ctx context.Context) *zap.SugaredLogger {
-	logger := Logger()
+func loggerWithContext(ctx context.Context) *zap.Logger {
+	state := currentState()
+	logger := state.logger
 
 	var extraFields []zap.Field
 
-	if reqID, ok := ctx.Value(RequestIDKey).(string); ok && reqID != "" {
+	if reqID := contextString(ctx, RequestIDKey); reqID != "" {
 		extraFields = append(extraFields, zap.String("request_id", reqID))
 	}
-	if userID, ok := ctx.Value(UserIDKey).(string); ok && userID != "" {
+	if userID := contextString(ctx, UserIDKey); userID != "" {
 		extraFields = append(extraFields, zap.String("user_id", userID))
 	}
-	if traceID, ok := ctx.Value(TraceIDKey).(string); ok && traceID != "" {
+	if traceID := contextString(ctx, TraceIDKey); traceID != "" {
 		extraFields = append(extraFields, zap.String("trace_id", traceID))
 	}
+	extraFields = append(extraFields, contextFields(ctx)...)
+	if state.includeContextError {
+		if err := ctx.Err(); err != nil {
+			extraFields = append(extraFields, zap.NamedError("ctx_err", err))
+		}
+	}
 
 	if len(extraFields) > 0 {
 		logger = logger.With(extraFields...)
 	}
-	return logger.Sugar()
+	return logger
+}
+
+func sugarWithContext(ctx context.Context) *zap.SugaredLogger {
+	return loggerWithContext(ctx).Sugar()
+}
+
+// FromContext returns a logger bound to the request/user/trace IDs in ctx.
+// The context is read once, so a handler that logs many times can reuse the
+// returned logger instead of calling the *Ctx functions repeatedly.
+func FromContext(ctx context.Context) *ZLogger {
+	logger := loggerWithContext(ctx)
+	return newZLogger(logger, currentState())
+}
+
+// SugarFromContext returns a sugared logger bound to the request/user/trace
+// IDs in ctx. Like FromContext, obtain it once per request and reuse it for
+// formatted logging instead of calling the *fCtx functions repeatedly.
+func SugarFromContext(ctx context.Context) *zap.SugaredLogger {
+	// Undo the caller skip meant for zlog's own wrappers, since callers use
+	// the returned logger directly
+	return loggerWithContext(ctx).WithOptions(zap.AddCallerSkip(-1)).Sugar()
 }
 
 func DebugCtx(ctx context.Context, msg string, fields ...Field) {
+	executeHooksCtx(ctx, DebugLevel, msg, fields)
 	loggerWithContext(ctx).Debug(msg, fields...)
 }
 
 func InfoCtx(ctx context.Context, msg string, fields ...Field) {
+	executeHooksCtx(ctx, InfoLevel, msg, fields)
 	loggerWithContext(ctx).Info(msg, fields...)
 }
 
 func WarnCtx(ctx context.Context, msg string, fields ...Field) {
+	executeHooksCtx(ctx, WarnLevel, msg, fields)
 	loggerWithContext(ctx).Warn(msg, fields...)
 }
 
 func ErrorCtx(ctx context.Context, msg string, fields ...Field) {
+	executeHooksCtx(ctx, ErrorLevel, msg, fields)
 	loggerWithContext(ctx).Error(msg, fields...)
 }
 
 func PanicCtx(ctx context.Context, msg string, fields ...Field) {
+	executeHooksCtx(ctx, PanicLevel, msg, fields)
 	loggerWithContext(ctx).Panic(msg, fields...)
 }
 
 func FatalCtx(ctx context.Context, msg string, fields ...Field) {
+	executeHooksCtx(ctx, FatalLevel, msg, fields)
 	loggerWithContext(ctx).Fatal(msg, fields...)
 }
 
 
 func DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	executeHooksCtx(ctx, DebugLevel, fmt.Sprintf(format, args...), nil)
 	sugarWithContext(ctx).Debugf(format, args...)
 }
 
 func InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	executeHooksCtx(ctx, InfoLevel, fmt.Sprintf(format, args...), nil)
 	sugarWithContext(ctx).Infof(format, args...)
 }
 
 func WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	executeHooksCtx(ctx, WarnLevel, fmt.Sprintf(format, args...), nil)
 	sugarWithContext(ctx).Warnf(format, args...)
 }
 
 func ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	executeHooksCtx(ctx, ErrorLevel, fmt.Sprintf(format, args...), nil)
 	sugarWithContext(ctx).Errorf(format, args...)
 }
 
 func PanicfCtx(ctx context.Context, format string, args ...interface{}) {
+	executeHooksCtx(ctx, PanicLevel, fmt.Sprintf(format, args...), nil)
 	sugarWithContext(ctx).Panicf(format, args...)
 }
 
 func FatalfCtx(ctx context.Context, format string, args ...interface{}) {
+	executeHooksCtx(ctx, FatalLevel, fmt.Sprintf(format, args...), nil)
 	sugarWithContext(ctx).Fatalf(format, args...)
 }
 
 
 func DebugwCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	executeHooksCtx(ctx, DebugLevel, msg, nil)
 	sugarWithContext(ctx).Debugw(msg, keysAndValues...)
 }
 
 func InfowCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	executeHooksCtx(ctx, InfoLevel, msg, nil)
 	sugarWithContext(ctx).Infow(msg, keysAndValues...)
 }
 
 func WarnwCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	executeHooksCtx(ctx, WarnLevel, msg, nil)
 	sugarWithContext(ctx).Warnw(msg, keysAndValues...)
 }
 
 func ErrorwCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	executeHooksCtx(ctx, ErrorLevel, msg, nil)
 	sugarWithContext(ctx).Errorw(msg, keysAndValues...)
 }
 
 func PanicwCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	executeHooksCtx(ctx, PanicLevel, msg, nil)
 	sugarWithContext(ctx).Panicw(msg, keysAndValues...)
 }
 
 func FatalwCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	executeHooksCtx(ctx, FatalLevel, msg, nil)
 	sugarWithContext(ctx).Fatalw(msg, keysAndValues...)
 }
@@ -0,0 +1,61 @@
+package zlog
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextString(t *testing.T) {
+	bg := context.Background()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"empty", bg, ""},
+		{"typed key", ContextWithRequestID(bg, "typed"), "typed"},
+		{"raw string key", context.WithValue(bg, "request_id", "raw"), "raw"},
+		{"typed wins", context.WithValue(ContextWithRequestID(bg, "typed"), "request_id", "raw"), "typed"},
+		{"empty typed falls back", context.WithValue(ContextWithRequestID(bg, ""), "request_id", "raw"), "raw"},
+		{"non-string typed falls back", context.WithValue(context.WithValue(bg, RequestIDKey, 42), "request_id", "raw"), "raw"},
+		{"non-string raw", context.WithValue(bg, "request_id", 42), ""},
+		{"other key", ContextWithUserID(bg, "user"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contextString(tt.ctx, RequestIDKey); got != tt.want {
+				t.Errorf("contextString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func benchContext() context.Context {
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	ctx = ContextWithTraceID(ctx, "trace-1")
+	return ContextWithUserID(ctx, "user-1")
+}
+
+// Each request logs several lines; InfofCtx reads the context on every call
+func BenchmarkInfofCtx(b *testing.B) {
+	useGlobal(b, testConfig(b))
+	ctx := benchContext()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 5; j++ {
+			InfofCtx(ctx, "step %d done", j)
+		}
+	}
+}
+
+func BenchmarkSugarFromContext(b *testing.B) {
+	useGlobal(b, testConfig(b))
+	ctx := benchContext()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sugar := SugarFromContext(ctx)
+		for j := 0; j < 5; j++ {
+			sugar.Infof("step %d done", j)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// EncoderConstructor builds an encoder from zlog's encoder config
+type EncoderConstructor func(zapcore.EncoderConfig) (zapcore.Encoder, error)
+
+var (
+	encoders = map[string]EncoderConstructor{
+		"json": func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return zapcore.NewJSONEncoder(cfg), nil
+		},
+		"console": func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return zapcore.NewConsoleEncoder(cfg), nil
+		},
+		"json-pretty": func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return &prettyJSONEncoder{Encoder: zapcore.NewJSONEncoder(cfg)}, nil
+		},
+		"gelf": newGELFEncoder,
+	}
+	encodersMutex sync.RWMutex
+)
+
+// RegisterEncoder makes a custom encoder available as a Format value.
+// It fails if name is empty or already registered.
+func RegisterEncoder(name string, constructor EncoderConstructor) error {
+	if name == "" {
+		return fmt.Errorf("encoder name is required")
+	}
+	if constructor == nil {
+		return fmt.Errorf("encoder %q: constructor is nil", name)
+	}
+	encodersMutex.Lock()
+	defer encodersMutex.Unlock()
+	if _, ok := encoders[name]; ok {
+		return fmt.Errorf("encoder %q is already registered", name)
+	}
+	encoders[name] = constructor
+	return nil
+}
+
+// lookupEncoder returns the constructor registered under name
+func lookupEncoder(name string) (EncoderConstructor, bool) {
+	encodersMutex.RLock()
+	defer encodersMutex.RUnlock()
+	constructor, ok := encoders[name]
+	return constructor, ok
+}
+
+var prettyBufferPool = buffer.NewPool()
+
+// prettyJSONEncoder indents the output of a JSON encoder. Every entry is
+// encoded and then re-parsed by json.Indent, which is several times slower
+// than plain JSON, so it is meant for local development only.
+type prettyJSONEncoder struct {
+	zapcore.Encoder
+}
+
+func (e *prettyJSONEncoder) Clone() zapcore.Encoder {
+	return &prettyJSONEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e *prettyJSONEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+
+	var indented bytes.Buffer
+	line := bytes.TrimRight(buf.Bytes(), "\r\n")
+	if err := json.Indent(&indented, line, "", "  "); err != nil {
+		// Should not happen; keep the compact line rather than lose the entry
+		out := prettyBufferPool.Get()
+		out.Write(buf.Bytes())
+		return out, nil
+	}
+	out := prettyBufferPool.Get()
+	out.Write(indented.Bytes())
+	out.Write(buf.Bytes()[len(line):])
+	return out, nil
+}
+
+// durationEncoder returns the zapcore.DurationEncoder for a DurationFormat value.
+// Unknown values fall back to seconds.
+func durationEncoder(format string) zapcore.DurationEncoder {
+	switch format {
+	case "millis":
+		return millisDurationEncoder
+	case "nanos":
+		return zapcore.NanosDurationEncoder
+	case "string":
+		return zapcore.StringDurationEncoder
+	default:
+		return zapcore.SecondsDurationEncoder
+	}
+}
+
+// millisDurationEncoder serializes a time.Duration to an integer number of milliseconds
+func millisDurationEncoder(d time.Duration, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendInt64(d.Milliseconds())
+}
+
+// callerEncoder returns the zapcore.CallerEncoder for a CallerEncoder value:
+// full path, base file name, or package/file (the default)
+func callerEncoder(format string) zapcore.CallerEncoder {
+	switch format {
+	case "full":
+		return zapcore.FullCallerEncoder
+	case "base":
+		return baseCallerEncoder
+	default:
+		return zapcore.ShortCallerEncoder
+	}
+}
+
+// baseCallerEncoder serializes a caller as file.go:line, without any directory
+func baseCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	if !caller.Defined {
+		enc.AppendString("undefined")
+		return
+	}
+	enc.AppendString(filepath.Base(caller.File) + ":" + strconv.Itoa(caller.Line))
+}
+
+// ansiColorNames maps color names accepted in LevelColors to ANSI codes
+var ansiColorNames = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+}
+
+// ansiColorCode resolves a color name or a raw ANSI code such as "1;31"
+func ansiColorCode(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if code, ok := ansiColorNames[color]; ok {
+		return code, nil
+	}
+	if color != "" && strings.Trim(color, "0123456789;") == "" {
+		return color, nil
+	}
+	return "", fmt.Errorf("invalid color %q", color)
+}
+
+// levelEncoders lists the LevelEncoder values
+var levelEncoders = map[string]bool{
+	"lowercase":      true,
+	"capital":        true,
+	"capitalColor":   true,
+	"lowercaseColor": true,
+}
+
+// levelEncoder returns the zapcore.LevelEncoder for a LevelEncoder value;
+// the color variants use colors like colorLevelEncoder
+func levelEncoder(name string, colors map[Level]string) zapcore.LevelEncoder {
+	switch name {
+	case "capital":
+		return zapcore.CapitalLevelEncoder
+	case "capitalColor":
+		return colorLevelEncoder(colors, true)
+	case "lowercaseColor":
+		return colorLevelEncoder(colors, false)
+	default:
+		return zapcore.LowercaseLevelEncoder
+	}
+}
+
+// colorLevelEncoder returns a colored level encoder, capitalized or not, that
+// uses colors for the levels it lists and zap's default colors for the rest.
+// Invalid entries are ignored.
+func colorLevelEncoder(colors map[Level]string, capital bool) zapcore.LevelEncoder {
+	fallback := zapcore.LowercaseColorLevelEncoder
+	if capital {
+		fallback = zapcore.CapitalColorLevelEncoder
+	}
+	if len(colors) == 0 {
+		return fallback
+	}
+	prefixes := make(map[zapcore.Level]string, len(colors))
+	for lvl, color := range colors {
+		code, err := ansiColorCode(color)
+		if err != nil || !lvl.Valid() {
+			continue
+		}
+		prefixes[lvl.toZapCoreLevel()] = "\x1b[" + code + "m"
+	}
+	return func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		prefix, ok := prefixes[l]
+		if !ok {
+			fallback(l, enc)
+			return
+		}
+		name := l.String()
+		if capital {
+			name = l.CapitalString()
+		}
+		enc.AppendString(prefix + name + "\x1b[0m")
+	}
+}
@@ -0,0 +1,69 @@
+package zlog
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// eventLogDestination is the sink destination of Output "eventlog"
+	eventLogDestination = "eventlog"
+	// defaultEventLogSource is the source name used when EventLogSource is empty
+	defaultEventLogSource = "zlog"
+	// eventLogID is the event ID of every entry written to the Event Log
+	eventLogID = 1
+)
+
+// eventLogger is the part of *eventlog.Log used by eventLogCore
+type eventLogger interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+	Close() error
+}
+
+// eventLogCore writes entries to the Windows Event Log, as Information,
+// Warning or Error events depending on their level
+type eventLogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	log eventLogger
+}
+
+func (c *eventLogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &eventLogCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), log: c.log}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *eventLogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *eventLogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	msg := strings.TrimRight(buf.String(), "\r\n")
+	switch {
+	case ent.Level >= zapcore.ErrorLevel:
+		return c.log.Error(eventLogID, msg)
+	case ent.Level == zapcore.WarnLevel:
+		return c.log.Warning(eventLogID, msg)
+	default:
+		return c.log.Info(eventLogID, msg)
+	}
+}
+
+func (c *eventLogCore) Sync() error {
+	return nil
+}
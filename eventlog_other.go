@@ -0,0 +1,10 @@
+//go:build !windows
+
+package zlog
+
+import "errors"
+
+// openEventLog fails everywhere but on Windows
+func openEventLog(source string) (eventLogger, error) {
+	return nil, errors.New("eventlog output is only supported on Windows")
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package zlog
+
+import "golang.org/x/sys/windows/svc/eventlog"
+
+// openEventLog opens the Event Log for source. The source must have been
+// registered, e.g. with eventlog.InstallAsEventCreate when installing the service.
+func openEventLog(source string) (eventLogger, error) {
+	return eventlog.Open(source)
+}
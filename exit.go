@@ -0,0 +1,78 @@
+package zlog
+
+import (
+	"os"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// flushHook syncs every output of a logger before handing the entry to next,
+// so the fatal or panic entry and everything buffered before it are written
+// before the process exits or unwinds
+type flushHook struct {
+	sync func() error // set once the logger is built
+	next zapcore.CheckWriteHook
+}
+
+func (h *flushHook) OnWrite(ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	if h.sync != nil {
+		_ = h.sync()
+	}
+	h.next.OnWrite(ce, fields)
+}
+
+var (
+	exitFunc  atomic.Pointer[func(int)]
+	panicFunc atomic.Pointer[func(string)]
+)
+
+// SetExitFunc replaces os.Exit as what Fatal calls, with code 1, after the
+// entry has been written, and what RecoverAndExit calls. A function that
+// returns lets the caller continue, so tests can assert on the fatal path
+// without a subprocess. nil restores os.Exit.
+func SetExitFunc(fn func(code int)) {
+	if fn == nil {
+		exitFunc.Store(nil)
+		return
+	}
+	exitFunc.Store(&fn)
+}
+
+// SetPanicFunc replaces the panic that Panic raises with the message after
+// the entry has been written. A function that returns lets the caller of
+// Panic continue. nil restores the panic.
+func SetPanicFunc(fn func(msg string)) {
+	if fn == nil {
+		panicFunc.Store(nil)
+		return
+	}
+	panicFunc.Store(&fn)
+}
+
+// exitWriteHook ends a fatal entry through SetExitFunc's function or os.Exit
+type exitWriteHook struct{}
+
+func (exitWriteHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	exit(1)
+}
+
+// exit calls SetExitFunc's function, or os.Exit
+func exit(code int) {
+	if fn := exitFunc.Load(); fn != nil {
+		(*fn)(code)
+		return
+	}
+	os.Exit(code)
+}
+
+// panicWriteHook ends a panic entry through SetPanicFunc's function or panic
+type panicWriteHook struct{}
+
+func (panicWriteHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	if fn := panicFunc.Load(); fn != nil {
+		(*fn)(ce.Message)
+		return
+	}
+	panic(ce.Message)
+}
@@ -1,18 +1,31 @@
 package zlog
 
 import (
+	"errors"
+	"maps"
+	"slices"
 	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
+// maxErrChainDepth bounds how many wrapped errors ErrChain walks
+const maxErrChainDepth = 32
+
 type fieldType int
 
 const (
 	fieldAny fieldType = iota
 	fieldString
 	fieldInt
+	fieldInt8
+	fieldInt16
+	fieldInt32
 	fieldInt64
+	fieldUint8
+	fieldUint16
+	fieldUint32
 	fieldBool
 	fieldFloat64
 	fieldDuration
@@ -25,9 +38,103 @@ type Field = zap.Field
 // Constructor functions
 func String(key, val string) Field                 { return zap.String(key, val) }
 func Int(key string, val int) Field                { return zap.Int(key, val) }
+func Int8(key string, val int8) Field              { return zap.Int8(key, val) }
+func Int16(key string, val int16) Field            { return zap.Int16(key, val) }
+func Int32(key string, val int32) Field            { return zap.Int32(key, val) }
 func Int64(key string, val int64) Field            { return zap.Int64(key, val) }
+func Uint8(key string, val uint8) Field            { return zap.Uint8(key, val) }
+func Uint16(key string, val uint16) Field          { return zap.Uint16(key, val) }
+func Uint32(key string, val uint32) Field          { return zap.Uint32(key, val) }
 func Bool(key string, val bool) Field              { return zap.Bool(key, val) }
 func Float64(key string, val float64) Field        { return zap.Float64(key, val) }
 func Duration(key string, val time.Duration) Field { return zap.Duration(key, val) }
 func Time(key string, val time.Time) Field         { return zap.Time(key, val) }
 func Any(key string, val interface{}) Field        { return zap.Any(key, val) }
+func Err(err error) Field                          { return zap.Error(err) }
+
+// LogFielder is implemented by errors that carry structured context, such as
+// a domain error with a code. When such an error is logged with Err (or any
+// error field), its LogFields are added to the entry after the error; errors
+// wrapping one are recognized as well.
+type LogFielder interface {
+	LogFields() []Field
+}
+
+// Stack returns a field holding the current stacktrace, starting at the
+// caller of Stack, so a single entry can carry a stack regardless of
+// StacktraceLevel:
+//
+//	zlog.Warn("slow query", zlog.Stack("stack"))
+func Stack(key string) Field {
+	return zap.StackSkip(key, 1)
+}
+
+// Skip returns a no-op field that emits nothing
+func Skip() Field { return zap.Skip() }
+
+// lazyValue is the Interface of a Lazy field
+type lazyValue func() interface{}
+
+// Lazy returns a field whose value is computed by fn only when the entry is
+// actually written, so expensive values cost nothing on disabled levels.
+// fn runs at most once per log call (once per With for child loggers) and
+// its result is logged like Any.
+func Lazy(key string, fn func() interface{}) Field {
+	if fn == nil {
+		return Skip()
+	}
+	return Field{Key: key, Type: zapcore.SkipType, Interface: lazyValue(fn)}
+}
+
+// CondField returns f when cond is true and Skip() otherwise, so optional
+// fields can be passed inline without branching
+func CondField(cond bool, f Field) Field {
+	if !cond {
+		return Skip()
+	}
+	return f
+}
+
+// StringMap logs val as a nested object with sorted keys, so output is
+// deterministic. A nil map is logged as an empty object.
+func StringMap(key string, val map[string]string) Field {
+	return zap.Object(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			enc.AddString(k, val[k])
+		}
+		return nil
+	}))
+}
+
+// AnyMap logs val as a nested object with sorted keys, encoding each value
+// like Any. A nil map is logged as an empty object.
+func AnyMap(key string, val map[string]interface{}) Field {
+	return zap.Object(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			zap.Any(k, val[k]).AddTo(enc)
+		}
+		return nil
+	}))
+}
+
+// ErrChain logs err as an object holding its message and the messages of every
+// error it wraps, outermost first, as found by errors.Unwrap. The walk stops
+// after maxErrChainDepth layers to guard against cyclic chains. A nil err is skipped.
+func ErrChain(key string, err error) Field {
+	if err == nil {
+		return Skip()
+	}
+	var chain []string
+	for e := err; e != nil && len(chain) < maxErrChainDepth; e = errors.Unwrap(e) {
+		chain = append(chain, e.Error())
+	}
+	return zap.Object(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("message", err.Error())
+		return enc.AddArray("chain", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, msg := range chain {
+				arr.AppendString(msg)
+			}
+			return nil
+		}))
+	}))
+}
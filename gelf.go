@@ -0,0 +1,111 @@
+package zlog
+
+import (
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// gelfVersion is the GELF specification version written in every entry
+const gelfVersion = "1.1"
+
+// gelfEncoder writes GELF 1.1 JSON for Graylog. It wraps a JSON encoder whose
+// standard keys follow the GELF names and prefixes every additional field
+// with "_", as the specification requires.
+type gelfEncoder struct {
+	zapcore.Encoder
+}
+
+func newGELFEncoder(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	cfg.MessageKey = "short_message"
+	cfg.TimeKey = "timestamp"
+	cfg.EncodeTime = zapcore.EpochTimeEncoder
+	cfg.LevelKey = "level"
+	cfg.EncodeLevel = gelfLevelEncoder
+	cfg.NameKey = "_logger"
+	cfg.CallerKey = "_caller"
+	cfg.StacktraceKey = "full_message"
+	cfg.FunctionKey = zapcore.OmitKey
+
+	enc := zapcore.NewJSONEncoder(cfg)
+	enc.AddString("version", gelfVersion)
+	enc.AddString("host", hostname())
+	return &gelfEncoder{Encoder: enc}, nil
+}
+
+// gelfLevelEncoder maps levels to syslog severities
+func gelfLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendInt(gelfSeverity(l))
+}
+
+func gelfSeverity(l zapcore.Level) int {
+	switch l {
+	case zapcore.DebugLevel:
+		return 7 // debug
+	case zapcore.InfoLevel:
+		return 6 // informational
+	case zapcore.WarnLevel:
+		return 4 // warning
+	case zapcore.ErrorLevel:
+		return 3 // error
+	default:
+		return 2 // critical: dpanic, panic and fatal
+	}
+}
+
+// gelfKey turns a field key into a GELF additional field name
+func gelfKey(key string) string {
+	return "_" + key
+}
+
+func (e *gelfEncoder) Clone() zapcore.Encoder {
+	return &gelfEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e *gelfEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	prefixed := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		f.Key = gelfKey(f.Key)
+		prefixed[i] = f
+	}
+	return e.Encoder.EncodeEntry(ent, prefixed)
+}
+
+// The methods below receive the fields added through With
+
+func (e *gelfEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.Encoder.AddArray(gelfKey(key), arr)
+}
+
+func (e *gelfEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return e.Encoder.AddObject(gelfKey(key), obj)
+}
+
+func (e *gelfEncoder) AddBinary(k string, v []byte)          { e.Encoder.AddBinary(gelfKey(k), v) }
+func (e *gelfEncoder) AddByteString(k string, v []byte)      { e.Encoder.AddByteString(gelfKey(k), v) }
+func (e *gelfEncoder) AddBool(k string, v bool)              { e.Encoder.AddBool(gelfKey(k), v) }
+func (e *gelfEncoder) AddComplex128(k string, v complex128)  { e.Encoder.AddComplex128(gelfKey(k), v) }
+func (e *gelfEncoder) AddComplex64(k string, v complex64)    { e.Encoder.AddComplex64(gelfKey(k), v) }
+func (e *gelfEncoder) AddDuration(k string, v time.Duration) { e.Encoder.AddDuration(gelfKey(k), v) }
+func (e *gelfEncoder) AddFloat64(k string, v float64)        { e.Encoder.AddFloat64(gelfKey(k), v) }
+func (e *gelfEncoder) AddFloat32(k string, v float32)        { e.Encoder.AddFloat32(gelfKey(k), v) }
+func (e *gelfEncoder) AddInt(k string, v int)                { e.Encoder.AddInt(gelfKey(k), v) }
+func (e *gelfEncoder) AddInt64(k string, v int64)            { e.Encoder.AddInt64(gelfKey(k), v) }
+func (e *gelfEncoder) AddInt32(k string, v int32)            { e.Encoder.AddInt32(gelfKey(k), v) }
+func (e *gelfEncoder) AddInt16(k string, v int16)            { e.Encoder.AddInt16(gelfKey(k), v) }
+func (e *gelfEncoder) AddInt8(k string, v int8)              { e.Encoder.AddInt8(gelfKey(k), v) }
+func (e *gelfEncoder) AddString(k string, v string)          { e.Encoder.AddString(gelfKey(k), v) }
+func (e *gelfEncoder) AddTime(k string, v time.Time)         { e.Encoder.AddTime(gelfKey(k), v) }
+func (e *gelfEncoder) AddUint(k string, v uint)              { e.Encoder.AddUint(gelfKey(k), v) }
+func (e *gelfEncoder) AddUint64(k string, v uint64)          { e.Encoder.AddUint64(gelfKey(k), v) }
+func (e *gelfEncoder) AddUint32(k string, v uint32)          { e.Encoder.AddUint32(gelfKey(k), v) }
+func (e *gelfEncoder) AddUint16(k string, v uint16)          { e.Encoder.AddUint16(gelfKey(k), v) }
+func (e *gelfEncoder) AddUint8(k string, v uint8)            { e.Encoder.AddUint8(gelfKey(k), v) }
+func (e *gelfEncoder) AddUintptr(k string, v uintptr)        { e.Encoder.AddUintptr(gelfKey(k), v) }
+
+func (e *gelfEncoder) AddReflected(key string, val interface{}) error {
+	return e.Encoder.AddReflected(gelfKey(key), val)
+}
+
+func (e *gelfEncoder) OpenNamespace(key string) { e.Encoder.OpenNamespace(gelfKey(key)) }
@@ -0,0 +1,63 @@
+package zlog
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	globalFields   atomic.Pointer[[]Field]
+	globalFieldsMu sync.Mutex
+)
+
+// SetGlobalField adds f to every subsequent log entry, replacing any global
+// field with the same key. Unlike LoggerConfig.Fields, which are fixed when the
+// logger is built, global fields can change while the process is running.
+func SetGlobalField(f Field) {
+	globalFieldsMu.Lock()
+	defer globalFieldsMu.Unlock()
+
+	var current []Field
+	if p := globalFields.Load(); p != nil {
+		current = *p
+	}
+	next := make([]Field, 0, len(current)+1)
+	for _, existing := range current {
+		if existing.Key != f.Key {
+			next = append(next, existing)
+		}
+	}
+	next = append(next, f)
+	globalFields.Store(&next)
+}
+
+// RemoveGlobalField stops adding the global field with the given key
+func RemoveGlobalField(key string) {
+	globalFieldsMu.Lock()
+	defer globalFieldsMu.Unlock()
+
+	p := globalFields.Load()
+	if p == nil {
+		return
+	}
+	next := make([]Field, 0, len(*p))
+	for _, existing := range *p {
+		if existing.Key != key {
+			next = append(next, existing)
+		}
+	}
+	globalFields.Store(&next)
+}
+
+// appendGlobalFields is the fieldProcessor that applies the global fields
+func appendGlobalFields(_ *zapcore.Entry, fields []Field) []Field {
+	p := globalFields.Load()
+	if p == nil || len(*p) == 0 {
+		return fields
+	}
+	out := make([]Field, 0, len(fields)+len(*p))
+	out = append(out, fields...)
+	return append(out, *p...)
+}
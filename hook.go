@@ -1,13 +1,18 @@
 package zlog
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
 )
 
 var (
 	globalHooks []LogHook
+	entryHooks  []EntryHook
 	hooksMutex  sync.RWMutex
 )
 
@@ -15,22 +20,142 @@ type LogHook interface {
 	OnLog(level Level, msg string, fields []Field) error
 }
 
+// ContextHook is implemented by a LogHook that wants the context of the
+// *Ctx logging functions, e.g. to read a request ID. Register it with
+// RegisterLogHook; OnLogCtx is called from the *Ctx functions and OnLog from
+// every other logging function.
+type ContextHook interface {
+	OnLogCtx(ctx context.Context, level Level, msg string, fields []Field) error
+}
+
+// HookEntry is a log entry as it is about to be written, with the real
+// timestamp and caller resolved
+type HookEntry struct {
+	Level   Level
+	Time    time.Time
+	Message string
+	Caller  string  // file:line, empty when caller is unavailable
+	Fields  []Field // those added by With, e.g. by the *Ctx functions, then the entry's own
+}
+
+// EntryHook receives fully-resolved entries. Unlike LogHook it is only called
+// for entries that are actually written (enabled and not dropped by sampling).
+type EntryHook interface {
+	OnEntry(e HookEntry)
+}
+
+// RegisterLogHook registers hook. A hook that also implements EntryHook
+// receives OnEntry instead of OnLog.
 func RegisterLogHook(hook LogHook) {
+	if eh, ok := hook.(EntryHook); ok {
+		RegisterEntryHook(eh)
+		return
+	}
 	hooksMutex.Lock()
 	defer hooksMutex.Unlock()
 	globalHooks = append(globalHooks, hook)
 }
 
+// RegisterEntryHook registers a hook that only implements EntryHook
+func RegisterEntryHook(hook EntryHook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	entryHooks = append(entryHooks, hook)
+}
+
 // executeHooks is called within logWithFields
 func executeHooks(zlogLevel Level, msg string, fields []Field) {
+	if globalNop.Load() {
+		return
+	}
+	runHooks(logHooks(), zlogLevel, msg, fields)
+}
+
+// runHooks calls OnLog of each of hooks, reporting errors on stderr
+func runHooks(hooks []LogHook, zlogLevel Level, msg string, fields []Field) {
+	for _, err := range callHooks(hooks, zlogLevel, msg, fields) {
+		fmt.Fprintf(os.Stderr, "[zlog] LogHook error: %v\n", err)
+	}
+}
+
+// callHooks calls OnLog of each of hooks and returns their errors
+func callHooks(hooks []LogHook, zlogLevel Level, msg string, fields []Field) []error {
+	var errs []error
+	for _, hook := range hooks {
+		if err := hook.OnLog(zlogLevel, msg, fields); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// LogWithHookErrors logs like Info, Error and the other level functions, but
+// returns the errors of the LogHooks instead of reporting them on stderr, for
+// paths such as auditing where a failing hook must not go unnoticed. It
+// returns nil if every hook succeeded.
+func LogWithHookErrors(level Level, msg string, fields ...Field) []error {
+	var errs []error
+	if !globalNop.Load() {
+		errs = callHooks(logHooks(), level, msg, fields)
+	}
+	Logger().Log(level.toZapCoreLevel(), msg, fields...)
+	return errs
+}
+
+// executeHooksCtx is executeHooks for the *Ctx functions; it passes ctx to
+// hooks implementing ContextHook
+func executeHooksCtx(ctx context.Context, zlogLevel Level, msg string, fields []Field) {
+	if globalNop.Load() {
+		return
+	}
+	for _, hook := range logHooks() {
+		var err error
+		if ch, ok := hook.(ContextHook); ok {
+			err = ch.OnLogCtx(ctx, zlogLevel, msg, fields)
+		} else {
+			err = hook.OnLog(zlogLevel, msg, fields)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[zlog] LogHook error: %v\n", err)
+		}
+	}
+}
+
+// logHooks returns a snapshot of the registered LogHooks
+func logHooks() []LogHook {
 	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
 	hooks := make([]LogHook, len(globalHooks))
 	copy(hooks, globalHooks)
+	return hooks
+}
+
+// executeEntryHooks dispatches a written entry to the EntryHooks, with the
+// fields added by With (context) ahead of the entry's own
+func executeEntryHooks(ent *zapcore.Entry, context, fields []Field) {
+	hooksMutex.RLock()
+	if len(entryHooks) == 0 {
+		hooksMutex.RUnlock()
+		return
+	}
+	hooks := make([]EntryHook, len(entryHooks))
+	copy(hooks, entryHooks)
 	hooksMutex.RUnlock()
 
+	e := HookEntry{
+		Level:   fromZapCoreLevel(ent.Level),
+		Time:    ent.Time,
+		Message: ent.Message,
+		Fields:  fields,
+	}
+	if len(context) > 0 {
+		e.Fields = make([]Field, 0, len(context)+len(fields))
+		e.Fields = append(append(e.Fields, context...), fields...)
+	}
+	if ent.Caller.Defined {
+		e.Caller = ent.Caller.TrimmedPath()
+	}
 	for _, hook := range hooks {
-		if err := hook.OnLog(zlogLevel, msg, fields); err != nil {
-			fmt.Fprintf(os.Stderr, "[zlog] LogHook error: %v\n", err)
-		}
+		hook.OnEntry(e)
 	}
 }
@@ -0,0 +1,44 @@
+package zlog
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// recordingEntryHook keeps the entries with message msg. Hooks cannot be
+// unregistered, so it ignores the entries of other tests.
+type recordingEntryHook struct {
+	msg     string
+	mu      sync.Mutex
+	entries []HookEntry
+}
+
+func (h *recordingEntryHook) OnEntry(e HookEntry) {
+	if e.Message != h.msg {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, e)
+}
+
+func TestEntryHookGetsWithFields(t *testing.T) {
+	useGlobal(t, testConfig(t))
+	hook := &recordingEntryHook{msg: "entry hook with fields"}
+	RegisterEntryHook(hook)
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	InfoCtx(ctx, hook.msg, String("k", "v"))
+
+	if len(hook.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(hook.entries))
+	}
+	got := map[string]string{}
+	for _, f := range hook.entries[0].Fields {
+		got[f.Key] = f.String
+	}
+	if got["request_id"] != "req-1" || got["k"] != "v" {
+		t.Errorf("fields = %v, want request_id and k", got)
+	}
+}
@@ -0,0 +1,159 @@
+package zlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// truncatedSuffix marks a body that was logged only in part
+const truncatedSuffix = "...(truncated)"
+
+// httpStatusLevel holds the func(int) Level used by LogHTTPResult
+var httpStatusLevel atomic.Value
+
+// DefaultHTTPStatusLevel maps 5xx to error, 4xx to warn and anything else to info
+func DefaultHTTPStatusLevel(status int) Level {
+	switch {
+	case status >= 500:
+		return ErrorLevel
+	case status >= 400:
+		return WarnLevel
+	default:
+		return InfoLevel
+	}
+}
+
+// SetHTTPStatusLevel replaces the status-to-level mapping of LogHTTPResult;
+// nil restores DefaultHTTPStatusLevel
+func SetHTTPStatusLevel(fn func(status int) Level) {
+	if fn == nil {
+		fn = DefaultHTTPStatusLevel
+	}
+	httpStatusLevel.Store(fn)
+}
+
+// LogHTTPResult logs msg with a status field at the level the status maps
+// to (see SetHTTPStatusLevel), with the request/user/trace IDs in ctx
+func LogHTTPResult(ctx context.Context, status int, msg string, fields ...Field) {
+	level := DefaultHTTPStatusLevel(status)
+	if fn, ok := httpStatusLevel.Load().(func(int) Level); ok {
+		level = fn(status)
+	}
+	if !level.Valid() {
+		level = InfoLevel
+	}
+	fields = append(fields[:len(fields):len(fields)], Int("status", status))
+	executeHooksCtx(ctx, level, msg, fields)
+	loggerWithContext(ctx).Log(level.toZapCoreLevel(), msg, fields...)
+}
+
+// LevelHandler returns an http.Handler that reports the global log level on GET
+// and changes it on PUT with a body like {"level":"debug"}.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(serveLevel)
+}
+
+func serveLevel(w http.ResponseWriter, r *http.Request) {
+	type errorResponse struct {
+		Error string `json:"error"`
+	}
+	type payload struct {
+		Level *Level `json:"level"`
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+
+	switch r.Method {
+	case http.MethodGet:
+		current := GetLevel()
+		_ = enc.Encode(payload{Level: &current})
+
+	case http.MethodPut:
+		var req payload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorResponse{Error: err.Error()})
+			return
+		}
+		if req.Level == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorResponse{Error: "must specify a logging level"})
+			return
+		}
+		if err := SetLevel(*req.Level); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorResponse{Error: err.Error()})
+			return
+		}
+		current := GetLevel()
+		_ = enc.Encode(payload{Level: &current})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = enc.Encode(errorResponse{Error: "only GET and PUT are supported"})
+	}
+}
+
+// BodyField reads up to maxBytes from r and returns them as a string field,
+// together with a reader that replays those bytes followed by the rest of r,
+// so the body can still be consumed downstream:
+//
+//	field, body := zlog.BodyField("request_body", r.Body, 1024)
+//	r.Body = io.NopCloser(body) // close the original body as usual
+//
+// Bodies longer than maxBytes end with "...(truncated)", and bodies that are
+// not valid UTF-8 are base64-encoded with a "base64:" prefix.
+func BodyField(key string, r io.Reader, maxBytes int) (Field, io.Reader) {
+	if r == nil {
+		return Skip(), r
+	}
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+
+	// Read one extra byte to find out whether the body is longer than maxBytes
+	buf := make([]byte, maxBytes+1)
+	n, err := io.ReadFull(r, buf)
+	buf = buf[:n]
+	var rest io.Reader = r
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		rest = &errReader{err: err}
+	}
+	replay := io.MultiReader(bytes.NewReader(buf), rest)
+
+	shown, truncated := buf, n > maxBytes
+	if truncated {
+		shown = buf[:maxBytes]
+		// Don't let the cut split a multi-byte character
+		for i := 0; i < utf8.UTFMax-1 && len(shown) > 0 && !utf8.Valid(shown); i++ {
+			shown = shown[:len(shown)-1]
+		}
+	}
+	var val string
+	if utf8.Valid(shown) {
+		val = string(shown)
+	} else {
+		val = "base64:" + base64.StdEncoding.EncodeToString(shown)
+	}
+	if truncated {
+		val += truncatedSuffix
+	}
+	return String(key, val), replay
+}
+
+// errReader returns err on every read
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,190 @@
+package zlog
+
+import (
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZLogger is a standalone logger with its own configuration, for subsystems
+// that should not share the global logger. (Logger() already returns the
+// global zap.Logger, hence the Z prefix.)
+type ZLogger struct {
+	base  *zap.Logger
+	sugar *zap.SugaredLogger
+	state *loggerState
+}
+
+// New builds a standalone logger from config
+func New(config LoggerConfig) (*ZLogger, error) {
+	state, err := newLogger(config)
+	if err != nil {
+		return nil, err
+	}
+	return newZLogger(state.logger, state), nil
+}
+
+// NewWithCore builds a standalone logger writing to core instead of the
+// outputs of a LoggerConfig, for packages such as zlogtest that bring their
+// own core. Fields are normalized like in New, entries below level, which
+// SetLevel changes, never reach core, and Fatal and Panic end through
+// SetExitFunc's and SetPanicFunc's functions.
+func NewWithCore(core zapcore.Core, level Level) *ZLogger {
+	if !level.Valid() {
+		level = InfoLevel
+	}
+	zapLevel := zap.NewAtomicLevelAt(level.toZapCoreLevel())
+	filtered := &levelFilterCore{Core: core, allow: zapLevel.Enabled}
+	fatalHook := &flushHook{next: exitWriteHook{}}
+	panicHook := &flushHook{next: panicWriteHook{}}
+	logger := zap.New(
+		newProcessorCore(filtered, zapcore.AddSync(io.Discard), processorChain{
+			with:  []fieldProcessor{toZapFields},
+			entry: []fieldProcessor{toZapFields, appendGlobalFields, appendLocalFields},
+		}),
+		zap.AddCaller(),
+		zap.AddCallerSkip(1),
+		zap.WithFatalHook(fatalHook),
+		zap.WithPanicHook(panicHook),
+	)
+	fatalHook.sync = logger.Core().Sync
+	panicHook.sync = logger.Core().Sync
+	state := &loggerState{logger: logger, level: zapLevel}
+	return newZLogger(logger, state)
+}
+
+func newZLogger(base *zap.Logger, state *loggerState) *ZLogger {
+	return &ZLogger{base: base, sugar: base.Sugar(), state: state}
+}
+
+// Zap returns the underlying zap.Logger, for direct use by callers
+func (l *ZLogger) Zap() *zap.Logger {
+	// Undo the caller skip meant for the ZLogger methods
+	return l.base.WithOptions(zap.AddCallerSkip(-1))
+}
+
+// Sugar returns the underlying zap.SugaredLogger, for direct use by callers
+func (l *ZLogger) Sugar() *zap.SugaredLogger {
+	return l.Zap().Sugar()
+}
+
+// With returns a child logger that adds fields to every entry
+func (l *ZLogger) With(fields ...Field) *ZLogger {
+	return newZLogger(l.base.With(fields...), l.state)
+}
+
+// Named returns a child logger whose name is extended with s, written in
+// the logger field as "parent.s"
+func (l *ZLogger) Named(s string) *ZLogger {
+	return newZLogger(l.base.Named(s), l.state)
+}
+
+// WithPrefix returns a child logger that writes every message as
+// "[p] message", a lightweight alternative to Named for grouping lines
+// visually. Prefixes of nested calls are written outermost first.
+func (l *ZLogger) WithPrefix(p string) *ZLogger {
+	return newZLogger(l.base.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newPrefixCore(core, p)
+	})), l.state)
+}
+
+// Enabled reports whether entries at level would be logged, so callers can
+// skip building expensive fields
+func (l *ZLogger) Enabled(level Level) bool {
+	return l.base.Core().Enabled(level.toZapCoreLevel())
+}
+
+// SetLevel changes the minimum level of this logger at runtime
+func (l *ZLogger) SetLevel(level Level) error {
+	if !level.Valid() {
+		return errInvalidLevel(level)
+	}
+	l.state.level.SetLevel(level.toZapCoreLevel())
+	return nil
+}
+
+// Sync flushes buffered logs
+func (l *ZLogger) Sync() error { return l.base.Sync() }
+
+// Rotate flushes this logger and rotates its log files right away
+func (l *ZLogger) Rotate() error { return l.state.rotate() }
+
+// Close flushes buffered logs and closes the log files of this logger.
+// The logger, and every logger derived from it, must not be used after Close.
+func (l *ZLogger) Close() error { return l.state.close() }
+
+// executeHooks runs the LogHooks for an entry of this logger, unless it is a no-op logger
+func (l *ZLogger) executeHooks(level Level, msg string, fields []Field) {
+	if l.state.nop {
+		return
+	}
+	runHooks(logHooks(), level, msg, fields)
+}
+
+// ========== Structured Logging ==========
+func (l *ZLogger) Debug(msg string, fields ...Field) {
+	l.executeHooks(DebugLevel, msg, fields)
+	l.base.Debug(msg, fields...)
+}
+func (l *ZLogger) Info(msg string, fields ...Field) {
+	l.executeHooks(InfoLevel, msg, fields)
+	l.base.Info(msg, fields...)
+}
+func (l *ZLogger) Warn(msg string, fields ...Field) {
+	l.executeHooks(WarnLevel, msg, fields)
+	l.base.Warn(msg, fields...)
+}
+func (l *ZLogger) Error(msg string, fields ...Field) {
+	l.executeHooks(ErrorLevel, msg, fields)
+	l.base.Error(msg, fields...)
+}
+func (l *ZLogger) Panic(msg string, fields ...Field) {
+	l.executeHooks(PanicLevel, msg, fields)
+	l.base.Panic(msg, fields...)
+}
+func (l *ZLogger) Fatal(msg string, fields ...Field) {
+	l.executeHooks(FatalLevel, msg, fields)
+	l.base.Fatal(msg, fields...)
+}
+
+// ========== Key-Value Logging ==========
+func (l *ZLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	l.executeHooks(DebugLevel, msg, nil)
+	l.sugar.Debugw(msg, keysAndValues...)
+}
+func (l *ZLogger) Infow(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	l.executeHooks(InfoLevel, msg, nil)
+	l.sugar.Infow(msg, keysAndValues...)
+}
+func (l *ZLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	l.executeHooks(WarnLevel, msg, nil)
+	l.sugar.Warnw(msg, keysAndValues...)
+}
+func (l *ZLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
+	l.executeHooks(ErrorLevel, msg, nil)
+	l.sugar.Errorw(msg, keysAndValues...)
+}
+
+// ========== Formatted Logging ==========
+func (l *ZLogger) Debugf(format string, args ...interface{}) {
+	l.executeHooks(DebugLevel, fmt.Sprintf(format, args...), nil)
+	l.sugar.Debugf(format, args...)
+}
+func (l *ZLogger) Infof(format string, args ...interface{}) {
+	l.executeHooks(InfoLevel, fmt.Sprintf(format, args...), nil)
+	l.sugar.Infof(format, args...)
+}
+func (l *ZLogger) Warnf(format string, args ...interface{}) {
+	l.executeHooks(WarnLevel, fmt.Sprintf(format, args...), nil)
+	l.sugar.Warnf(format, args...)
+}
+func (l *ZLogger) Errorf(format string, args ...interface{}) {
+	l.executeHooks(ErrorLevel, fmt.Sprintf(format, args...), nil)
+	l.sugar.Errorf(format, args...)
+}
@@ -0,0 +1,85 @@
+package zlog
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestNewSamplesPerLogger(t *testing.T) {
+	sampledCfg := testConfig(t)
+	sampledCfg.Sampling = true
+	sampled, err := New(sampledCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCfg := testConfig(t)
+	otherCfg.Sampling = true
+	other, err := New(otherCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawCfg := testConfig(t)
+	raw, err := New(rawCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 150; i++ {
+		sampled.Info("same message")
+		raw.Info("same message")
+	}
+	// other has its own counters, so sampled having used up the first
+	// samplingFirst entries of this second must not affect it
+	for i := 0; i < 50; i++ {
+		other.Info("same message")
+	}
+	for _, l := range []*ZLogger{sampled, other, raw} {
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Crossing a second boundary resets the counters, so allow for it
+	if got := countLines(t, sampledCfg.FilePath); got < samplingFirst || got >= 150 {
+		t.Errorf("sampled logger wrote %d lines, want %d up to 149", got, samplingFirst)
+	}
+	if got := countLines(t, otherCfg.FilePath); got != 50 {
+		t.Errorf("second sampled logger wrote %d lines, want 50", got)
+	}
+	if got := countLines(t, rawCfg.FilePath); got != 150 {
+		t.Errorf("unsampled logger wrote %d lines, want 150", got)
+	}
+}
+
+func TestNewReleasesOutputsOnError(t *testing.T) {
+	before := runtime.NumGoroutine()
+	cfg := testConfig(t)
+	cfg.Sinks = []SinkConfig{
+		{Destination: "tcp://127.0.0.1:1", Format: "json"},
+		{Destination: "stdout", Route: RouteConfig{Field: "tenant"}}, // routing needs a file
+	}
+	if l, err := New(cfg); err == nil {
+		l.Close()
+		t.Fatal("New accepted an invalid sink")
+	}
+	// The network writer's goroutines exit
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines, want at most %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
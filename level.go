@@ -35,9 +35,10 @@ func (l Level) Valid() bool {
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
-// Supports parsing from YAML, JSON, TOML, env vars, etc.
+// Supports parsing from YAML, JSON, TOML, env vars, etc. Case and
+// surrounding whitespace are ignored.
 func (l *Level) UnmarshalText(text []byte) error {
-	levelStr := strings.ToLower(string(text))
+	levelStr := strings.ToLower(strings.TrimSpace(string(text)))
 	switch levelStr {
 	case "debug", "d":
 		*l = DebugLevel
@@ -52,11 +53,15 @@ func (l *Level) UnmarshalText(text []byte) error {
 	case "fatal", "f":
 		*l = FatalLevel
 	default:
-		return fmt.Errorf("invalid log level: %q", string(text))
+		return errInvalidLevel(Level(text))
 	}
 	return nil
 }
 
+func errInvalidLevel(l Level) error {
+	return fmt.Errorf("invalid log level: %q", string(l))
+}
+
 func (l Level) MarshalText() ([]byte, error) {
 	if !l.Valid() {
 		return []byte("info"), nil // safe default
@@ -84,6 +89,29 @@ func (l Level) toZapCoreLevel() zapcore.Level {
 	}
 }
 
+// Enabled reports whether the global logger would log entries at level, so
+// callers can skip building expensive fields:
+//
+//	if zlog.Enabled(zlog.DebugLevel) { ... }
+func Enabled(level Level) bool {
+	return Logger().Core().Enabled(level.toZapCoreLevel())
+}
+
+// SetLevel changes the minimum level of the global logger at runtime.
+// Outputs with their own ConsoleLevel or FileLevel keep that level.
+func SetLevel(level Level) error {
+	if !level.Valid() {
+		return errInvalidLevel(level)
+	}
+	currentState().level.SetLevel(level.toZapCoreLevel())
+	return nil
+}
+
+// GetLevel returns the current minimum level of the global logger
+func GetLevel() Level {
+	return fromZapCoreLevel(currentState().level.Level())
+}
+
 // fromZapCoreLevel converts from zapcore.Level (if needed)
 func fromZapCoreLevel(l zapcore.Level) Level {
 	switch l {
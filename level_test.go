@@ -0,0 +1,18 @@
+package zlog
+
+import "testing"
+
+func FuzzLevelUnmarshalText(f *testing.F) {
+	for _, s := range []string{"debug", " INFO ", "warning", "e", "Fatal", "", "trace", "\xff"} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, text []byte) {
+		var l Level
+		if err := l.UnmarshalText(text); err != nil {
+			return
+		}
+		if !l.Valid() {
+			t.Fatalf("UnmarshalText(%q) = %q, which is not a valid level", text, l)
+		}
+	})
+}
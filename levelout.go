@@ -0,0 +1,93 @@
+package zlog
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WriterConfig is the destination of one level in OutputsByLevel
+type WriterConfig struct {
+	Destination string `yaml:"destination"` // stdout、stderr、tcp://host:port、udp://host:port, or a file path
+	Format      string `yaml:"format"`      // defaults to LoggerConfig.Format
+	Color       bool   `yaml:"color"`       // colored levels, console format only
+
+	Network NetworkConfig `yaml:"network"` // tcp:// and udp:// destinations only
+}
+
+// validateOutputsByLevel checks the OutputsByLevel entries
+func validateOutputsByLevel(outputs map[Level]WriterConfig) error {
+	for lvl, w := range outputs {
+		if !lvl.Valid() {
+			return fmt.Errorf("OutputsByLevel: %w", errInvalidLevel(lvl))
+		}
+		if w.Destination == "" {
+			return fmt.Errorf("OutputsByLevel[%s]: Destination is required", lvl)
+		}
+		if w.Destination == eventLogDestination {
+			return fmt.Errorf("OutputsByLevel[%s]: eventlog is not supported", lvl)
+		}
+		if isNetworkDestination(w.Destination) {
+			if _, _, err := parseNetworkDestination(w.Destination); err != nil {
+				return fmt.Errorf("OutputsByLevel[%s]: %w", lvl, err)
+			}
+		}
+	}
+	return nil
+}
+
+// levelCores restricts the default cores to the levels missing from outputs
+// and adds a core for each level in outputs that only writes that level.
+// Outputs sharing a file or stream share its writer, so they may overlap.
+func (b *sinkBuilder) levelCores(defaults []zapcore.Core, outputs map[Level]WriterConfig) ([]zapcore.Core, error) {
+	mapped := make(map[zapcore.Level]bool, len(outputs))
+	levels := make([]Level, 0, len(outputs))
+	for lvl := range outputs {
+		if lvl.Valid() {
+			mapped[lvl.toZapCoreLevel()] = true
+			levels = append(levels, lvl)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].toZapCoreLevel() < levels[j].toZapCoreLevel() })
+
+	cores := make([]zapcore.Core, 0, len(defaults)+len(levels))
+	for _, c := range defaults {
+		cores = append(cores, &levelFilterCore{Core: c, allow: func(l zapcore.Level) bool { return !mapped[l] }})
+	}
+	for _, lvl := range levels {
+		w := outputs[lvl]
+		format := w.Format
+		if format == "" {
+			format = b.cfg.Format
+		}
+		c, err := b.build(SinkConfig{Destination: w.Destination, Format: format, Color: w.Color, Network: w.Network})
+		if err != nil {
+			return nil, fmt.Errorf("OutputsByLevel[%s]: %w", lvl, err)
+		}
+		only := lvl.toZapCoreLevel()
+		cores = append(cores, &levelFilterCore{Core: c, allow: func(l zapcore.Level) bool { return l == only }})
+	}
+	return cores, nil
+}
+
+// levelFilterCore passes on only the entries whose level satisfies allow
+type levelFilterCore struct {
+	zapcore.Core
+	allow func(zapcore.Level) bool
+}
+
+func (c *levelFilterCore) Enabled(lvl zapcore.Level) bool {
+	return c.allow(lvl) && c.Core.Enabled(lvl)
+}
+
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), allow: c.allow}
+}
+
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.allow(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,49 @@
+package zlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputsByLevelRoutesListedLevels(t *testing.T) {
+	dir := t.TempDir()
+	cfg := testConfig(t)
+	cfg.Level = DebugLevel
+	cfg.OutputsByLevel = map[Level]WriterConfig{
+		ErrorLevel: {Destination: filepath.Join(dir, "error.log")},
+		DebugLevel: {Destination: filepath.Join(dir, "debug.log"), Format: "console"},
+	}
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("d")
+	l.Info("i")
+	l.Warn("w")
+	l.Error("e")
+	l.Close()
+
+	// Levels without an output keep the default one
+	var msgs []interface{}
+	for _, e := range readEntries(t, cfg.FilePath) {
+		msgs = append(msgs, e["msg"])
+	}
+	if len(msgs) != 2 || msgs[0] != "i" || msgs[1] != "w" {
+		t.Errorf("default output got %v, want [i w]", msgs)
+	}
+
+	errs := readEntries(t, filepath.Join(dir, "error.log"))
+	if len(errs) != 1 || errs[0]["msg"] != "e" {
+		t.Errorf("error.log = %v, want only the error entry", errs)
+	}
+	// The output's own Format overrides the logger's
+	data, err := os.ReadFile(filepath.Join(dir, "debug.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 1 || strings.HasPrefix(lines[0], "{") {
+		t.Errorf("debug.log = %q, want one console line", data)
+	}
+}
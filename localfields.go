@@ -0,0 +1,96 @@
+package zlog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Goroutine-local fields, keyed by goroutine ID. localFieldsCount lets the
+// processor skip the goroutine ID lookup while no goroutine has any.
+var (
+	localFields      sync.Map // goroutine ID -> []Field
+	localFieldsCount atomic.Int64
+)
+
+// SetLocalFields adds fields to every subsequent entry logged from the
+// calling goroutine, replacing local fields with the same key, until
+// ClearLocalFields is called on that goroutine.
+//
+// This avoids threading a context through every call, at a cost:
+//   - The fields do not follow work handed to other goroutines; pass them on
+//     explicitly or use ContextWithFields, which does.
+//   - Go reuses goroutine IDs, so a goroutine that exits without calling
+//     ClearLocalFields leaks its fields to a later goroutine with the same ID.
+//     Always pair the calls: zlog.SetLocalFields(...); defer zlog.ClearLocalFields().
+//   - Finding the goroutine ID takes a stack dump, which makes every log call
+//     noticeably slower while any goroutine has local fields set.
+//
+// Prefer ContextWithFields where a context is already at hand.
+func SetLocalFields(fields ...Field) {
+	if len(fields) == 0 {
+		return
+	}
+	id := goroutineID()
+	var current []Field
+	if v, ok := localFields.Load(id); ok {
+		current = v.([]Field)
+	}
+	next := make([]Field, 0, len(current)+len(fields))
+	for _, existing := range current {
+		replaced := false
+		for _, f := range fields {
+			if f.Key == existing.Key {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			next = append(next, existing)
+		}
+	}
+	next = append(next, fields...)
+	if _, loaded := localFields.Swap(id, next); !loaded {
+		localFieldsCount.Add(1)
+	}
+}
+
+// ClearLocalFields removes the local fields of the calling goroutine
+func ClearLocalFields() {
+	if _, loaded := localFields.LoadAndDelete(goroutineID()); loaded {
+		localFieldsCount.Add(-1)
+	}
+}
+
+// appendLocalFields is the fieldProcessor that applies the local fields of
+// the logging goroutine. zap writes entries on the caller's goroutine, so
+// this runs where the fields were set.
+func appendLocalFields(_ *zapcore.Entry, fields []Field) []Field {
+	if localFieldsCount.Load() == 0 {
+		return fields
+	}
+	v, ok := localFields.Load(goroutineID())
+	if !ok {
+		return fields
+	}
+	local := v.([]Field)
+	out := make([]Field, 0, len(fields)+len(local))
+	out = append(out, fields...)
+	return append(out, local...)
+}
+
+// goroutineID parses the current goroutine's ID from the "goroutine N [" header of its stack
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
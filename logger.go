@@ -3,48 +3,139 @@ package zlog
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// Global instances (for backward compatibility)
+// Global instance, swapped atomically so SetNop can replace it while other
+// goroutines log
 var (
-	globalLogger        *zap.Logger
-	globalSugaredLogger *zap.SugaredLogger
-	once                sync.Once
+	globalState atomic.Pointer[loggerState]
+	once        sync.Once
 )
 
+// loggerState is a built logger together with the handles needed to adjust it at runtime
+type loggerState struct {
+	logger   *zap.Logger
+	sugar    *zap.SugaredLogger // set by setGlobal, for Sugar
+	level    zap.AtomicLevel
+	closers  []func() error   // run in order by close, after a final sync
+	consoles []*consoleSwitch // stdout/stderr outputs, for SetFormat
+	rotators []rotator        // file outputs, for Rotate
+
+	includeContextError bool
+	nop                 bool // built by NewNop or SetNop; hooks are skipped
+
+	stopFlush chan struct{} // closed to stop the periodic Sync, nil if not running
+	stopRate  chan struct{} // closed to stop the WarnRateThreshold check, nil if not running
+	closeOnce sync.Once
+}
+
+// startFlusher calls Sync every interval until close
+func (s *loggerState) startFlusher(interval time.Duration) {
+	s.stopFlush = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopFlush:
+				return
+			case <-ticker.C:
+				_ = s.logger.Sync()
+			}
+		}
+	}()
+}
+
+// stop ends the periodic Sync and the WarnRateThreshold check
+func (s *loggerState) stop() {
+	s.closeOnce.Do(func() {
+		if s.stopFlush != nil {
+			close(s.stopFlush)
+		}
+		if s.stopRate != nil {
+			close(s.stopRate)
+		}
+	})
+}
+
+// close flushes the logger and releases its writers
+func (s *loggerState) close() error {
+	s.stop()
+	err := s.logger.Sync()
+	for _, c := range s.closers {
+		if cerr := c(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// setGlobal installs state as the global logger and returns the previous one
+func setGlobal(state *loggerState) *loggerState {
+	state.sugar = state.logger.Sugar()
+	return globalState.Swap(state)
+}
+
+// currentState returns the global logger's state, initializing it if needed
+func currentState() *loggerState {
+	if s := globalState.Load(); s != nil {
+		return s
+	}
+	once.Do(func() {
+		cfg := DefaultConfig()
+		state, _ := newLogger(cfg)
+		state.sugar = state.logger.Sugar()
+		// SetNop may have installed its logger in the meantime
+		if !globalState.CompareAndSwap(nil, state) {
+			_ = state.close()
+		}
+	})
+	return globalState.Load()
+}
+
 // newLogger creates a new zap.Logger instance with automatic config validation,
 // default value filling, and path resolution.
 // internal helper, not exported
-func newLogger(config LoggerConfig) (*zap.Logger, error) {
+func newLogger(config LoggerConfig, opts ...zap.Option) (*loggerState, error) {
 	cfg := config
+	if cfg.Strict {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Normalize log level
-	if cfg.Level < DebugLevel || cfg.Level > FatalLevel {
+	if !cfg.Level.Valid() {
 		cfg.Level = InfoLevel
 	}
+	if cfg.ConsoleLevel != "" && !cfg.ConsoleLevel.Valid() {
+		cfg.ConsoleLevel = ""
+	}
+	if cfg.FileLevel != "" && !cfg.FileLevel.Valid() {
+		cfg.FileLevel = ""
+	}
 
 	// Normalize output destination
 	switch cfg.Output {
-	case "console", "file", "both":
+	case "console", "file", "both", "eventlog":
 		// valid
 	default:
 		cfg.Output = "console"
 	}
 
 	// Normalize format
-	if cfg.Format != "json" && cfg.Format != "console" {
+	if _, ok := lookupEncoder(cfg.Format); !ok {
 		cfg.Format = "console"
 	}
 
 	// Validate file path when needed
-	if (cfg.Output == "file" || cfg.Output == "both") && cfg.FilePath == "" {
+	if len(cfg.Sinks) == 0 && (cfg.Output == "file" || cfg.Output == "both") && cfg.FilePath == "" {
 		return nil, fmt.Errorf("file path is required when output is 'file' or 'both'")
 	}
 
@@ -58,22 +149,13 @@ func newLogger(config LoggerConfig) (*zap.Logger, error) {
 	if cfg.MaxAge < 0 {
 		cfg.MaxAge = 30 // days
 	}
-
-	// Resolve relative file path to absolute
-	if cfg.FilePath != "" && !filepath.IsAbs(cfg.FilePath) {
-		wd, err := os.Getwd()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get working directory: %w", err)
-		}
-		cfg.FilePath = filepath.Join(wd, cfg.FilePath)
+	// A layout that cannot be parsed back would make retention delete
+	// every backup, so it is rejected even without Strict
+	if err := validateBackupNaming(cfg.BackupTimeFormat, cfg.BackupSeparator); err != nil {
+		return nil, err
 	}
-
-	// Create log directory if needed
-	if cfg.FilePath != "" {
-		dir := filepath.Dir(cfg.FilePath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create log directory %q: %w", dir, err)
-		}
+	if _, err := rotateLocation(cfg.RotateLocation); err != nil {
+		return nil, err
 	}
 
 	// 4. Build encoder config
@@ -88,66 +170,124 @@ func newLogger(config LoggerConfig) (*zap.Logger, error) {
 		LineEnding:     zapcore.DefaultLineEnding,
 		EncodeLevel:    zapcore.LowercaseLevelEncoder,
 		EncodeTime:     zapcore.ISO8601TimeEncoder,
-		EncodeDuration: zapcore.SecondsDurationEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeDuration: durationEncoder(cfg.DurationFormat),
+		EncodeCaller:   callerEncoder(cfg.CallerEncoder),
+	}
+
+	if cfg.IncludeFunction && !cfg.SplitCaller {
+		encoderConfig.FunctionKey = "function"
 	}
 
 	// 5. Build cores
-	var cores []zapcore.Core
-	zapLevel := cfg.Level.toZapCoreLevel()
-
-	// Console output
-	if cfg.Output == "console" || cfg.Output == "both" {
-		var enc zapcore.Encoder
-		consoleEncCfg := encoderConfig
-		if cfg.Format == "json" {
-			enc = zapcore.NewJSONEncoder(consoleEncCfg)
-		} else {
-			consoleEncCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
-			enc = zapcore.NewConsoleEncoder(consoleEncCfg)
-		}
-		cores = append(cores, zapcore.NewCore(enc, zapcore.Lock(os.Stdout), zapLevel))
+	errOutput := zapcore.Lock(os.Stderr)
+	zapLevel := zap.NewAtomicLevelAt(cfg.Level.toZapCoreLevel())
+	builder := &sinkBuilder{
+		cfg:           cfg,
+		encoderConfig: encoderConfig,
+		level:         zapLevel,
+		errOutput:     errOutput,
 	}
 
-	// File output
-	if cfg.Output == "file" || cfg.Output == "both" {
-		writer := &lumberjack.Logger{
-			Filename:   cfg.FilePath,
-			MaxSize:    cfg.MaxSize,
-			MaxBackups: cfg.MaxBackups,
-			MaxAge:     cfg.MaxAge,
-			Compress:   cfg.Compress,
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = legacySinks(cfg)
+	}
+	var cores []zapcore.Core
+	for _, sink := range sinks {
+		c, err := builder.build(sink)
+		if err != nil {
+			builder.close()
+			return nil, err
 		}
-		var enc zapcore.Encoder
-		consoleEncCfg := encoderConfig
-		if cfg.Format == "json" {
-			enc = zapcore.NewJSONEncoder(consoleEncCfg)
-		} else {
-			enc = zapcore.NewConsoleEncoder(consoleEncCfg)
+		cores = append(cores, c)
+	}
+	if len(cfg.OutputsByLevel) > 0 {
+		var err error
+		if cores, err = builder.levelCores(cores, cfg.OutputsByLevel); err != nil {
+			builder.close()
+			return nil, err
 		}
-		cores = append(cores, zapcore.NewCore(enc, zapcore.AddSync(writer), zapLevel))
 	}
+	closers := builder.closers
 
 	if len(cores) == 0 {
 		return nil, fmt.Errorf("no valid log output configured")
 	}
 
 	// 6. Build logger
-	core := zapcore.NewTee(cores...)
+	stacktraceLevel := cfg.StacktraceLevel
+	if !stacktraceLevel.Valid() {
+		stacktraceLevel = ErrorLevel
+	}
+	chain := processorChain{
+		with:       []fieldProcessor{toZapFields},
+		entry:      []fieldProcessor{toZapFields, appendGlobalFields, appendLocalFields},
+		entryHooks: true,
+	}
+	if cfg.SanitizeMessages {
+		chain.with = append(chain.with, sanitizeEntry)
+		chain.entry = append(chain.entry, sanitizeEntry)
+	}
+	if cfg.MaxFieldBytes > 0 {
+		chain.with = append(chain.with, truncateFields(cfg.MaxFieldBytes, false))
+		chain.entry = append(chain.entry, truncateFields(cfg.MaxFieldBytes, cfg.TruncateMessage))
+	}
+	if cfg.StacktraceAsArray {
+		chain.entry = append(chain.entry, stacktraceAsArray)
+	}
+	if cfg.IncludeUptime {
+		chain.entry = append(chain.entry, uptimeProcessor(processStart))
+	}
+	if cfg.IncludeGoroutineID {
+		chain.entry = append(chain.entry, appendGoroutineID)
+	}
+	var rate *rateWatch
+	if cfg.WarnRateThreshold > 0 {
+		rate = &rateWatch{threshold: int64(cfg.WarnRateThreshold)}
+		chain.entry = append(chain.entry, rate.countEntry)
+	}
+	if cfg.SplitCaller {
+		// After the entry hooks, which still get the caller as one string
+		chain.afterHooks = append(chain.afterHooks, splitCaller(cfg.IncludeFunction))
+	}
+	core := newProcessorCore(zapcore.NewTee(cores...), errOutput, chain)
+	if cfg.MessagePrefix != "" {
+		core = newPrefixCore(core, cfg.MessagePrefix)
+	}
+	fatalHook := &flushHook{next: exitWriteHook{}}
+	panicHook := &flushHook{next: panicWriteHook{}}
 	options := []zap.Option{
 		zap.AddCaller(),
 		zap.AddCallerSkip(1),
-		zap.AddStacktrace(zapcore.ErrorLevel),
-		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.AddStacktrace(stacktraceLevel.toZapCoreLevel()),
+		zap.ErrorOutput(errOutput),
+		zap.WithFatalHook(fatalHook),
+		zap.WithPanicHook(panicHook),
 	}
 
 	if cfg.Sampling {
+		sample := sampledLevels(cfg)
 		options = append(options, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
-			return zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+			var sampled zapcore.Core
+			if cfg.SamplingPerKey {
+				sampled = newKeyedSampler(core, samplingTick, samplingFirst, samplingThereafter, recordSamplingDecision)
+			} else {
+				sampled = zapcore.NewSamplerWithOptions(core, samplingTick, samplingFirst, samplingThereafter,
+					zapcore.SamplerHook(recordSamplingDecision))
+			}
+			return newLevelSplitCore(sampled, core, sample)
 		}))
 	}
 
+	// User options go last so they override the ones above
+	options = append(options, opts...)
 	logger := zap.New(core, options...)
+	fatalHook.sync = logger.Core().Sync
+	panicHook.sync = logger.Core().Sync
+
+	if cfg.Name != "" {
+		logger = logger.Named(cfg.Name)
+	}
 
 	// Add fixed fields
 	if len(cfg.Fields) > 0 {
@@ -155,17 +295,65 @@ func newLogger(config LoggerConfig) (*zap.Logger, error) {
 			logger = logger.WithOptions(zap.Fields(String(k, v)))
 		}
 	}
+	if cfg.IncludeHostname {
+		key := cfg.HostnameKey
+		if key == "" {
+			key = "hostname"
+		}
+		logger = logger.WithOptions(zap.Fields(String(key, hostname())))
+	}
+	if cfg.IncludePID {
+		logger = logger.WithOptions(zap.Fields(Int("pid", os.Getpid())))
+	}
+	if cfg.IncludeUptime {
+		logger = logger.WithOptions(zap.Fields(Time("process_start", processStart)))
+	}
 
-	return logger, nil
+	state := &loggerState{
+		logger:              logger,
+		level:               zapLevel,
+		closers:             closers,
+		consoles:            builder.consoles,
+		rotators:            builder.rotators,
+		includeContextError: cfg.IncludeContextError,
+	}
+	if cfg.FlushInterval > 0 {
+		state.startFlusher(cfg.FlushInterval)
+	}
+	if rate != nil {
+		state.startRateWatch(rate)
+	}
+	return state, nil
+}
+
+// coreLevel returns the enabler for a single output: its own fixed level when
+// set, otherwise the shared atomic level
+func coreLevel(level Level, shared zap.AtomicLevel) zapcore.LevelEnabler {
+	if level == "" {
+		return shared
+	}
+	return level.toZapCoreLevel()
 }
 
 // InitLogger initializes global logger (thread-safe)
 func InitLogger(config LoggerConfig) error {
+	return InitLoggerWithOptions(config)
+}
+
+// InitLoggerWithOptions initializes the global logger like InitLogger, then
+// applies opts on top of the options zlog sets itself. This is an escape hatch
+// for zap options zlog does not expose, such as zap.Hooks or zap.Development.
+// Following zap's semantics, later options override earlier ones.
+func InitLoggerWithOptions(config LoggerConfig, opts ...zap.Option) error {
+	if globalNop.Load() {
+		return errGlobalNop
+	}
 	var err error
 	once.Do(func() {
-		globalLogger, err = newLogger(config)
+		var state *loggerState
+		state, err = newLogger(config, opts...)
 		if err == nil {
-			globalSugaredLogger = globalLogger.Sugar()
+			setGlobal(state)
 		}
 	})
 	return err
@@ -173,20 +361,12 @@ func InitLogger(config LoggerConfig) error {
 
 // Logger returns global zap.Logger
 func Logger() *zap.Logger {
-	if globalLogger == nil {
-		once.Do(func() {
-			cfg := DefaultConfig()
-			globalLogger, _ = newLogger(cfg)
-			globalSugaredLogger = globalLogger.Sugar()
-		})
-	}
-	return globalLogger
+	return currentState().logger
 }
 
 // Sugar returns global SugaredLogger
 func Sugar() *zap.SugaredLogger {
-	_ = Logger() // Trigger initialization
-	return globalSugaredLogger
+	return currentState().sugar
 }
 
 // InitDefault initializes with default configuration
@@ -194,6 +374,11 @@ func InitDefault() error {
 	return InitLogger(DefaultConfig())
 }
 
+// InitDevelopment initializes with DevelopmentConfig
+func InitDevelopment() error {
+	return InitLogger(DevelopmentConfig())
+}
+
 // MustInitDefault panics if default logger fails to initialize.
 // Useful in main() for fail-fast behavior.
 func MustInitDefault() {
@@ -207,3 +392,16 @@ func Sync() error {
 	logger := Logger() // Trigger default initialization if not already initialized
 	return logger.Sync()
 }
+
+// Rotate flushes the global logger and rotates its log files right away,
+// e.g. before archiving them or from a SIGUSR1 handler. It returns an error
+// if the logger writes to no rotating file.
+func Rotate() error {
+	return currentState().rotate()
+}
+
+// Close flushes any buffered logs and closes the log files.
+// The global logger must not be used after Close.
+func Close() error {
+	return currentState().close()
+}
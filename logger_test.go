@@ -0,0 +1,32 @@
+package zlog
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// testConfig returns a config writing JSON to a file in a temporary directory
+func testConfig(tb testing.TB) LoggerConfig {
+	cfg := DefaultConfig()
+	cfg.Output = "file"
+	cfg.Format = "json"
+	cfg.FilePath = filepath.Join(tb.TempDir(), "app.log")
+	rotate := false
+	cfg.Rotate = &rotate
+	return cfg
+}
+
+// useGlobal installs a logger built from cfg as the global logger until the
+// test ends
+func useGlobal(tb testing.TB, cfg LoggerConfig) {
+	tb.Helper()
+	state, err := newLogger(cfg)
+	if err != nil {
+		tb.Fatalf("newLogger: %v", err)
+	}
+	prev := setGlobal(state)
+	tb.Cleanup(func() {
+		state.close()
+		globalState.Store(prev)
+	})
+}
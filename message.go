@@ -0,0 +1,56 @@
+package zlog
+
+import "sync"
+
+var (
+	messages   = make(map[string]string)
+	messagesMu sync.RWMutex
+)
+
+// RegisterMessage registers template as the message logged for id by
+// InfoMsg and the other *Msg functions, replacing any earlier template.
+// Keeping the wording in one place keeps it consistent across a large code
+// base, and the msg_id field makes every occurrence greppable by id.
+func RegisterMessage(id, template string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	messages[id] = template
+}
+
+// message returns the template registered for id, or id itself
+func message(id string) string {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	if tmpl, ok := messages[id]; ok {
+		return tmpl
+	}
+	return id
+}
+
+// msgFields returns fields with the msg_id field appended, without
+// modifying the caller's slice
+func msgFields(id string, fields []Field) []Field {
+	return append(fields[:len(fields):len(fields)], String("msg_id", id))
+}
+
+// Message-id logging functions: the message is the template registered for id
+func DebugMsg(id string, fields ...Field) {
+	msg, fields := message(id), msgFields(id, fields)
+	executeHooks(DebugLevel, msg, fields)
+	Logger().Debug(msg, fields...)
+}
+func InfoMsg(id string, fields ...Field) {
+	msg, fields := message(id), msgFields(id, fields)
+	executeHooks(InfoLevel, msg, fields)
+	Logger().Info(msg, fields...)
+}
+func WarnMsg(id string, fields ...Field) {
+	msg, fields := message(id), msgFields(id, fields)
+	executeHooks(WarnLevel, msg, fields)
+	Logger().Warn(msg, fields...)
+}
+func ErrorMsg(id string, fields ...Field) {
+	msg, fields := message(id), msgFields(id, fields)
+	executeHooks(ErrorLevel, msg, fields)
+	Logger().Error(msg, fields...)
+}
@@ -0,0 +1,63 @@
+package zlog
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// MetricsHook receives the updates made by Counter and Gauge fields, e.g. to
+// forward them to a metrics backend
+type MetricsHook interface {
+	Counter(key string, delta int64)
+	Gauge(key string, value float64)
+}
+
+var (
+	metricsHooks   atomic.Pointer[[]MetricsHook]
+	metricsHooksMu sync.Mutex
+)
+
+// RegisterMetricsHook registers hook to receive every Counter and Gauge update
+func RegisterMetricsHook(hook MetricsHook) {
+	if hook == nil {
+		return
+	}
+	metricsHooksMu.Lock()
+	defer metricsHooksMu.Unlock()
+	var hooks []MetricsHook
+	if old := metricsHooks.Load(); old != nil {
+		hooks = append(hooks, *old...)
+	}
+	hooks = append(hooks, hook)
+	metricsHooks.Store(&hooks)
+}
+
+// Counter returns an int64 field holding delta and adds delta to the counter
+// key of every registered MetricsHook, so one call both logs and counts:
+//
+//	zlog.Info("order placed", zlog.Counter("orders", 1))
+//
+// The metric is updated when the field is built, so it stays accurate even
+// when the entry itself is filtered out by level or sampling.
+func Counter(key string, delta int64) Field {
+	if hooks := metricsHooks.Load(); hooks != nil {
+		for _, h := range *hooks {
+			h.Counter(key, delta)
+		}
+	}
+	return zap.Int64(key, delta)
+}
+
+// Gauge returns a float64 field holding value and sets the gauge key of every
+// registered MetricsHook to it. Like Counter, the metric is updated when the
+// field is built.
+func Gauge(key string, value float64) Field {
+	if hooks := metricsHooks.Load(); hooks != nil {
+		for _, h := range *hooks {
+			h.Gauge(key, value)
+		}
+	}
+	return zap.Float64(key, value)
+}
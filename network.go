@@ -0,0 +1,551 @@
+package zlog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	networkDialTimeout     = 5 * time.Second
+	networkWriteTimeout    = 5 * time.Second
+	networkMinBackoff      = 500 * time.Millisecond
+	networkMaxBackoff      = 30 * time.Second
+	defaultRetryInterval   = 5 * time.Second
+	defaultDeadLetterMaxMB = 100
+	deadLetterChunkBytes   = 64 * 1024 // replayed per acquisition of the writer's lock
+)
+
+// NetworkConfig configures a sink whose Destination is a tcp:// or udp:// address
+type NetworkConfig struct {
+	DeadLetterPath    string        `yaml:"dead_letter_path"`     // entries that cannot be sent are kept here and replayed later; empty drops them
+	DeadLetterMaxSize int           `yaml:"dead_letter_max_size"` // MB before the dead-letter file is rotated; defaults to 100
+	RetryInterval     time.Duration `yaml:"retry_interval"`       // how often the dead-letter file is replayed; defaults to 5s
+	Compress          bool          `yaml:"compress"`             // gzip the stream (tcp) or each datagram (udp); the collector must accept gzip
+	CompressLevel     int           `yaml:"compress_level"`       // gzip level from 1 (fastest) to 9 (smallest); 0 means gzip's default
+}
+
+// isNetworkDestination reports whether dest is a tcp:// or udp:// address
+func isNetworkDestination(dest string) bool {
+	return strings.HasPrefix(dest, "tcp://") || strings.HasPrefix(dest, "udp://")
+}
+
+// parseNetworkDestination splits a tcp:// or udp:// destination into network and address
+func parseNetworkDestination(dest string) (network, addr string, err error) {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid network destination %q: %w", dest, err)
+	}
+	if u.Scheme != "tcp" && u.Scheme != "udp" {
+		return "", "", fmt.Errorf("invalid network destination %q: scheme must be tcp or udp", dest)
+	}
+	if _, _, err := net.SplitHostPort(u.Host); err != nil {
+		return "", "", fmt.Errorf("invalid network destination %q: %w", dest, err)
+	}
+	return u.Scheme, u.Host, nil
+}
+
+var errNotConnected = errors.New("network sink: not connected")
+
+// networkWriter sends entries to a collector, reconnecting with exponential
+// backoff. Connections are dialed by a background goroutine, so a collector
+// that is slow to answer never holds up a logging call; entries written while
+// there is no connection fail, or go to the dead-letter file. Only the first
+// dial, when the writer is created, is waited for.
+//
+// With Compress, a TCP connection carries one gzip stream that is flushed
+// after every entry, and every UDP datagram is a gzip member of its own.
+//
+// With a dead-letter file, entries that cannot be sent are appended to it and
+// a background replayer resends them, in order, once the collector is
+// reachable again. Until the replay completes new entries go to the
+// dead-letter file as well, so the collector never sees them out of order.
+// The replay sends at most deadLetterChunkBytes per acquisition of the lock,
+// so logging calls keep going while a large file is replayed. Replay progress
+// is kept in memory and written back to the file by Close; after a crash,
+// entries already replayed may be sent again.
+// TCP only reports a dropped connection on a later write, so the entry
+// written just as the collector goes away can still be lost.
+type networkWriter struct {
+	mu       sync.Mutex
+	network  string
+	addr     string
+	conn     net.Conn
+	backoff  time.Duration
+	nextDial time.Time
+	dialReq  chan struct{} // asks dialLoop to connect
+
+	compress bool
+	level    int
+	gz       *gzip.Writer // over conn, or over buf for udp
+	buf      bytes.Buffer // compressed datagram, udp only
+
+	deadLetter *deadLetterFile
+	pending    bool // the dead-letter file holds entries not yet replayed
+	done       chan struct{}
+	wg         sync.WaitGroup
+	closeOnce  sync.Once
+}
+
+func newNetworkWriter(dest string, cfg NetworkConfig) (*networkWriter, error) {
+	network, addr, err := parseNetworkDestination(dest)
+	if err != nil {
+		return nil, err
+	}
+	w := &networkWriter{
+		network:  network,
+		addr:     addr,
+		dialReq:  make(chan struct{}, 1),
+		compress: cfg.Compress,
+		level:    gzip.DefaultCompression,
+		done:     make(chan struct{}),
+	}
+	if cfg.CompressLevel != 0 {
+		if cfg.CompressLevel < gzip.BestSpeed || cfg.CompressLevel > gzip.BestCompression {
+			return nil, fmt.Errorf("invalid network CompressLevel %d: must be between 1 and 9", cfg.CompressLevel)
+		}
+		w.level = cfg.CompressLevel
+	}
+	interval := cfg.RetryInterval
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+	if cfg.DeadLetterPath != "" {
+		path, err := prepareLogFile(cfg.DeadLetterPath)
+		if err != nil {
+			return nil, err
+		}
+		maxSize := cfg.DeadLetterMaxSize
+		if maxSize <= 0 {
+			maxSize = defaultDeadLetterMaxMB
+		}
+		w.deadLetter = &deadLetterFile{path: path, maxSize: int64(maxSize) * 1024 * 1024}
+		// Entries left over from a previous run are replayed as well
+		w.pending = w.deadLetter.hasData()
+	}
+
+	// Connect up front so that the first entries do not fail while the
+	// background dialer gets going
+	w.dial()
+	w.wg.Add(1)
+	go w.dialLoop()
+	if w.deadLetter != nil {
+		w.wg.Add(1)
+		go w.replayLoop(interval)
+	}
+	return w, nil
+}
+
+func (w *networkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.pending {
+		err := w.send(p)
+		if err == nil {
+			return len(p), nil
+		}
+		if w.deadLetter == nil {
+			return 0, err
+		}
+	}
+	if _, err := w.deadLetter.Write(p); err != nil {
+		return 0, err
+	}
+	w.pending = true
+	return len(p), nil
+}
+
+// send writes p to the connection. Without a connection it asks dialLoop for
+// one and fails. Callers hold mu.
+func (w *networkWriter) send(p []byte) error {
+	if w.conn == nil {
+		w.requestDial()
+		return errNotConnected
+	}
+	w.conn.SetWriteDeadline(time.Now().Add(networkWriteTimeout))
+	if err := w.write(p); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		w.delayDial()
+		w.requestDial()
+		return err
+	}
+	w.backoff = 0
+	return nil
+}
+
+// write sends p on the connection, compressed if configured. Callers hold mu.
+func (w *networkWriter) write(p []byte) error {
+	switch {
+	case !w.compress:
+		_, err := w.conn.Write(p)
+		return err
+	case w.network == "tcp":
+		if _, err := w.gz.Write(p); err != nil {
+			return err
+		}
+		return w.gz.Flush()
+	default:
+		w.buf.Reset()
+		w.resetGzip(&w.buf)
+		w.gz.Write(p)
+		if err := w.gz.Close(); err != nil {
+			return err
+		}
+		_, err := w.conn.Write(w.buf.Bytes())
+		return err
+	}
+}
+
+// resetGzip points the gzip writer at dst, starting a new gzip stream
+func (w *networkWriter) resetGzip(dst io.Writer) {
+	if w.gz == nil {
+		// The level was checked in newNetworkWriter
+		w.gz, _ = gzip.NewWriterLevel(dst, w.level)
+		return
+	}
+	w.gz.Reset(dst)
+}
+
+// requestDial wakes dialLoop unless a request is already queued
+func (w *networkWriter) requestDial() {
+	select {
+	case w.dialReq <- struct{}{}:
+	default:
+	}
+}
+
+// dialLoop connects whenever requestDial asks for it, once the backoff has passed
+func (w *networkWriter) dialLoop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.dialReq:
+		}
+		w.mu.Lock()
+		connected := w.conn != nil
+		wait := time.Until(w.nextDial)
+		w.mu.Unlock()
+		if connected {
+			continue
+		}
+		if wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-w.done:
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+		w.dial()
+	}
+}
+
+// dial connects to the collector. mu is only taken once the dial is over.
+func (w *networkWriter) dial() {
+	conn, err := net.DialTimeout(w.network, w.addr, networkDialTimeout)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.delayDial()
+		return
+	}
+	w.conn = conn
+	if w.compress && w.network == "tcp" {
+		w.resetGzip(conn)
+	}
+}
+
+// delayDial doubles the reconnect delay, up to networkMaxBackoff
+func (w *networkWriter) delayDial() {
+	if w.backoff == 0 {
+		w.backoff = networkMinBackoff
+	} else if w.backoff < networkMaxBackoff {
+		w.backoff *= 2
+		if w.backoff > networkMaxBackoff {
+			w.backoff = networkMaxBackoff
+		}
+	}
+	w.nextDial = time.Now().Add(w.backoff)
+}
+
+func (w *networkWriter) replayLoop(interval time.Duration) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.replay()
+		}
+	}
+}
+
+// replay resends the dead-letter file chunk by chunk, releasing mu between
+// chunks, until it is empty or a send fails
+func (w *networkWriter) replay() {
+	for {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+		w.mu.Lock()
+		more := false
+		if w.pending {
+			if w.conn == nil {
+				w.requestDial()
+			} else if done, err := w.deadLetter.replayChunk(w.send, deadLetterChunkBytes); err == nil {
+				w.pending = !done
+				more = !done
+			}
+		}
+		w.mu.Unlock()
+		if !more {
+			return
+		}
+	}
+}
+
+func (w *networkWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.deadLetter != nil {
+		return w.deadLetter.Sync()
+	}
+	return nil
+}
+
+// Close stops the dialer and the replayer and closes the connection. Entries
+// still in the dead-letter file are replayed by the next writer using the
+// same path. Closing again is a no-op.
+func (w *networkWriter) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	w.wg.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var err error
+	if w.conn != nil {
+		if w.compress && w.network == "tcp" {
+			// End the gzip stream so the collector sees a complete one
+			w.gz.Close()
+		}
+		err = w.conn.Close()
+		w.conn = nil
+	}
+	if w.deadLetter != nil {
+		if cerr := w.deadLetter.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// deadLetterFile is an append-only file capped at maxSize. When full it is
+// renamed to path.1, replacing the previous backup, so at most twice maxSize
+// is kept on disk and the oldest entries are dropped first.
+type deadLetterFile struct {
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+
+	// Bytes at the start of the backup and the current file that have
+	// already been replayed; Close removes them from the files
+	sentBackup  int64
+	sentCurrent int64
+}
+
+func (d *deadLetterFile) backupPath() string {
+	return d.path + ".1"
+}
+
+func (d *deadLetterFile) hasData() bool {
+	for _, path := range []string{d.backupPath(), d.path} {
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *deadLetterFile) Write(p []byte) (int, error) {
+	if d.file == nil {
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	if d.size > 0 && d.size+int64(len(p)) > d.maxSize {
+		if err := d.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := d.file.Write(p)
+	d.size += int64(n)
+	return n, err
+}
+
+func (d *deadLetterFile) open() error {
+	f, err := os.OpenFile(d.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat dead-letter file: %w", err)
+	}
+	d.file, d.size = f, info.Size()
+	return nil
+}
+
+func (d *deadLetterFile) rotate() error {
+	d.closeFile()
+	if err := os.Rename(d.path, d.backupPath()); err != nil {
+		return fmt.Errorf("failed to rotate dead-letter file: %w", err)
+	}
+	// The current file's progress moves with it; the old backup is gone
+	d.sentBackup, d.sentCurrent = d.sentCurrent, 0
+	return d.open()
+}
+
+// replayChunk sends up to max bytes of whole lines through send, from the
+// backup first and then the current file, continuing where the previous
+// chunk stopped. A file that has been sent completely is deleted. It reports
+// whether both files are done.
+func (d *deadLetterFile) replayChunk(send func([]byte) error, max int64) (bool, error) {
+	path, sent := d.backupPath(), &d.sentBackup
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		path, sent = d.path, &d.sentCurrent
+	}
+	n, eof, err := replayFrom(path, *sent, max, send)
+	*sent += n
+	if err != nil || !eof {
+		return false, err
+	}
+	if path == d.path {
+		d.closeFile()
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	*sent = 0
+	return path == d.path, nil
+}
+
+// replayFrom sends the lines of path starting at offset through send until
+// about max bytes have gone out. It returns the bytes sent and whether the
+// end of the file was reached.
+func replayFrom(path string, offset, max int64, send func([]byte) error) (n int64, eof bool, err error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, true, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, false, err
+	}
+
+	r := bufio.NewReader(f)
+	for n < max {
+		line, rerr := r.ReadBytes('\n')
+		if len(line) > 0 {
+			if err := send(line); err != nil {
+				return n, false, err
+			}
+			n += int64(len(line))
+		}
+		if rerr == io.EOF {
+			return n, true, nil
+		}
+		if rerr != nil {
+			return n, false, rerr
+		}
+	}
+	return n, false, nil
+}
+
+// trimSent drops the first offset bytes of path, which have already been sent
+func trimSent(path string, offset int64) error {
+	if offset == 0 {
+		return nil
+	}
+	src, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, src)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (d *deadLetterFile) Sync() error {
+	if d.file == nil {
+		return nil
+	}
+	return d.file.Sync()
+}
+
+// Close closes the file and removes the entries already replayed from both
+// files, so the next writer does not send them again
+func (d *deadLetterFile) Close() error {
+	err := d.closeFile()
+	for _, f := range []struct {
+		path string
+		sent *int64
+	}{{d.backupPath(), &d.sentBackup}, {d.path, &d.sentCurrent}} {
+		if terr := trimSent(f.path, *f.sent); terr != nil {
+			fmt.Fprintf(os.Stderr, "[zlog] failed to trim dead-letter file %s, entries may be sent twice: %v\n", f.path, terr)
+			continue
+		}
+		*f.sent = 0
+	}
+	return err
+}
+
+func (d *deadLetterFile) closeFile() error {
+	if d.file == nil {
+		return nil
+	}
+	err := d.file.Close()
+	d.file, d.size = nil, 0
+	return err
+}
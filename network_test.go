@@ -0,0 +1,120 @@
+package zlog
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetworkWriterReplaysDeadLetter(t *testing.T) {
+	// Reserve an address with nothing listening on it
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	path := filepath.Join(t.TempDir(), "dead.log")
+	w, err := newNetworkWriter("tcp://"+addr, NetworkConfig{DeadLetterPath: path, RetryInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != "first\nsecond\n" {
+		t.Fatalf("dead-letter file = %q, want both entries", data)
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("address %s was taken: %v", addr, err)
+	}
+	defer ln.Close()
+	ln.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+
+	// Entries written during the replay queue up behind it
+	if _, err := w.Write([]byte("third\n")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"first\n", "second\n", "third\n"} {
+		got, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading %q: %v", want, err)
+		}
+		if got != want {
+			t.Fatalf("collector got %q, want %q", got, want)
+		}
+	}
+	// The file is removed right after its last line is sent
+	deadline := time.Now().Add(time.Second)
+	for _, err := os.Stat(path); !os.IsNotExist(err); _, err = os.Stat(path) {
+		if time.Now().After(deadline) {
+			t.Fatalf("dead-letter file still exists after the replay: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDeadLetterCloseTrimsReplayed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.log")
+	d := &deadLetterFile{path: path, maxSize: 1 << 20}
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := d.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sent []string
+	send := func(p []byte) error {
+		sent = append(sent, string(p))
+		return nil
+	}
+	// A chunk stops after the first line that reaches max
+	done, err := d.replayChunk(send, 1)
+	if err != nil || done {
+		t.Fatalf("replayChunk = %v, %v; want a partial chunk", done, err)
+	}
+	if len(sent) != 1 || sent[0] != "first\n" {
+		t.Fatalf("sent %q, want the first line", sent)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second\nthird\n" {
+		t.Errorf("dead-letter file after Close = %q, want the unsent lines", data)
+	}
+
+	// The next writer picks up where the replay stopped
+	d = &deadLetterFile{path: path, maxSize: 1 << 20}
+	sent = nil
+	if done, err := d.replayChunk(send, 1<<20); err != nil || !done {
+		t.Fatalf("replayChunk = %v, %v; want the rest", done, err)
+	}
+	if got := strings.Join(sent, ""); got != "second\nthird\n" {
+		t.Errorf("replayed %q after reopening, want the unsent lines", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("dead-letter file still exists after a full replay: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package zlog
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// globalNop is set once SetNop has installed a no-op global logger
+var globalNop atomic.Bool
+
+var errGlobalNop = errors.New("global logger was replaced by SetNop")
+
+// newNopState returns the state of a logger that discards everything. Fatal
+// and Panic still end through SetExitFunc's and SetPanicFunc's functions.
+func newNopState() *loggerState {
+	logger := zap.NewNop().WithOptions(
+		zap.WithFatalHook(exitWriteHook{}),
+		zap.WithPanicHook(panicWriteHook{}),
+	)
+	return &loggerState{logger: logger, level: zap.NewAtomicLevel(), nop: true}
+}
+
+// NewNop returns a logger that discards every entry and skips the hooks,
+// for tests and benchmarks that should not pay for logging
+func NewNop() *ZLogger {
+	state := newNopState()
+	return newZLogger(state.logger, state)
+}
+
+// SetNop replaces the global logger with one that discards every entry and
+// skips the hooks, so code under benchmark exercises the logging API at
+// almost no cost. A later InitLogger returns an error. The previous global
+// logger is flushed and its periodic Sync and rate check are stopped, but its
+// files stay open for loggers already derived from it.
+func SetNop() {
+	globalNop.Store(true)
+	prev := setGlobal(newNopState())
+	once.Do(func() {}) // keep Logger and InitLogger from replacing it
+	if prev != nil {
+		prev.stop()
+		_ = prev.logger.Sync()
+	}
+}
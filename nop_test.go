@@ -0,0 +1,71 @@
+package zlog
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestNopFatalAndPanicUseHooks(t *testing.T) {
+	var code int
+	var msg string
+	SetExitFunc(func(c int) { code = c })
+	SetPanicFunc(func(m string) { msg = m })
+	defer SetExitFunc(nil)
+	defer SetPanicFunc(nil)
+
+	l := NewNop()
+	l.Fatal("fatal")
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	l.Panic("panic")
+	if msg != "panic" {
+		t.Errorf("panic message = %q, want %q", msg, "panic")
+	}
+}
+
+// SetNop cannot be undone, so TestSetNop runs it in a child process
+func TestSetNop(t *testing.T) {
+	if os.Getenv("ZLOG_TEST_SETNOP") != "1" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestSetNop$")
+		cmd.Env = append(os.Environ(), "ZLOG_TEST_SETNOP=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("child process: %v\n%s", err, out)
+		}
+		return
+	}
+
+	cfg := testConfig(t)
+	cfg.FlushInterval = 10 * time.Millisecond
+	cfg.WarnRateThreshold = 1000
+	if err := InitLogger(cfg); err != nil {
+		t.Fatal(err)
+	}
+	before := runtime.NumGoroutine()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			Info("racing SetNop")
+		}
+	}()
+	SetNop()
+	<-done
+
+	if err := InitLogger(cfg); !errors.Is(err, errGlobalNop) {
+		t.Errorf("InitLogger after SetNop = %v, want %v", err, errGlobalNop)
+	}
+	// The flusher and the rate check of the previous logger exit
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before-2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines, want at most %d", runtime.NumGoroutine(), before-2)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,80 @@
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxCachedObjects bounds the CachedObject cache; it is emptied when full
+const maxCachedObjects = 1024
+
+// Versioned is implemented by objects passed to CachedObject that change
+// over time; a new LogVersion makes CachedObject encode them again
+type Versioned interface {
+	LogVersion() uint64
+}
+
+type cachedObject struct {
+	version uint64
+	raw     json.RawMessage
+}
+
+var (
+	objectCache   = make(map[interface{}]cachedObject)
+	objectCacheMu sync.RWMutex
+	// cacheEncoderConfig encodes just the object: every entry key is empty
+	cacheEncoderConfig = zapcore.EncoderConfig{
+		EncodeTime:     zapcore.EpochTimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+	}
+)
+
+// CachedObject logs val like zap.Object, but encodes it to JSON only once
+// and reuses the bytes every time the same val is logged again, for stable
+// structures such as a configuration logged on every request. Objects are
+// told apart by identity, so val should be a pointer (or another comparable
+// value); values that are not comparable are encoded every time.
+//
+// The cache assumes val does not change after it is first logged. An object
+// that does change must implement Versioned and return a new LogVersion on
+// every change. Times and durations are encoded as epoch seconds, whatever
+// the logger's encoder settings.
+func CachedObject(key string, val zapcore.ObjectMarshaler) Field {
+	// The value, not just its type: a comparable struct type can still hold
+	// a slice in an interface field, which would panic as a map key
+	if val == nil || !reflect.ValueOf(val).Comparable() {
+		return zap.Object(key, val)
+	}
+	var version uint64
+	if v, ok := val.(Versioned); ok {
+		version = v.LogVersion()
+	}
+
+	objectCacheMu.RLock()
+	cached, ok := objectCache[val]
+	objectCacheMu.RUnlock()
+	if ok && cached.version == version {
+		return zap.Reflect(key, cached.raw)
+	}
+
+	enc := zapcore.NewJSONEncoder(cacheEncoderConfig)
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{zap.Inline(val)})
+	if err != nil {
+		return zap.Object(key, val)
+	}
+	raw := json.RawMessage(bytes.TrimSpace(append([]byte(nil), buf.Bytes()...)))
+	buf.Free()
+
+	objectCacheMu.Lock()
+	if len(objectCache) >= maxCachedObjects {
+		objectCache = make(map[interface{}]cachedObject)
+	}
+	objectCache[val] = cachedObject{version: version, raw: raw}
+	objectCacheMu.Unlock()
+	return zap.Reflect(key, raw)
+}
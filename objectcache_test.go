@@ -0,0 +1,81 @@
+package zlog
+
+import (
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type benchConfig struct {
+	Name    string
+	Port    int
+	Debug   bool
+	Servers []string
+}
+
+func (c *benchConfig) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", c.Name)
+	enc.AddInt("port", c.Port)
+	enc.AddBool("debug", c.Debug)
+	return enc.AddArray("servers", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, s := range c.Servers {
+			arr.AppendString(s)
+		}
+		return nil
+	}))
+}
+
+// holder has a comparable type, but not when extra holds a slice
+type holder struct {
+	extra interface{}
+}
+
+func (h holder) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return enc.AddReflected("extra", h.extra)
+}
+
+func TestCachedObjectUnhashableValue(t *testing.T) {
+	f := CachedObject("h", holder{extra: []int{1, 2}})
+	if f.Type != zapcore.ObjectMarshalerType {
+		t.Fatalf("got field type %v, want an uncached object", f.Type)
+	}
+}
+
+func newBenchLogger() *zap.Logger {
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(discard{}),
+		zapcore.DebugLevel,
+	)
+	return zap.New(core)
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+var benchCfg = func() *benchConfig {
+	c := &benchConfig{Name: "api", Port: 8080}
+	for i := 0; i < 64; i++ {
+		c.Servers = append(c.Servers, fmt.Sprintf("backend-%02d.internal:8080", i))
+	}
+	return c
+}()
+
+func BenchmarkObject(b *testing.B) {
+	logger := newBenchLogger()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Info("request", zap.Object("config", benchCfg))
+	}
+}
+
+func BenchmarkCachedObject(b *testing.B) {
+	logger := newBenchLogger()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Info("request", CachedObject("config", benchCfg))
+	}
+}
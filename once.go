@@ -0,0 +1,89 @@
+package zlog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// seenOnce holds the keys already logged by the *Once functions
+var seenOnce sync.Map
+
+// lastEvery holds the last log time, in unix nanoseconds, of each key used
+// with the *Every functions
+var lastEvery sync.Map
+
+// firstTime reports whether key is seen for the first time, marking it as seen
+func firstTime(key string) bool {
+	_, loaded := seenOnce.LoadOrStore(key, struct{}{})
+	return !loaded
+}
+
+// InfoOnce logs at info level only the first time it is called with key
+func InfoOnce(key string, msg string, fields ...Field) {
+	if !firstTime(key) {
+		return
+	}
+	executeHooks(InfoLevel, msg, fields)
+	Logger().Info(msg, fields...)
+}
+
+// WarnOnce logs at warn level only the first time it is called with key,
+// which suits deprecation notices and similar one-off warnings
+func WarnOnce(key string, msg string, fields ...Field) {
+	if !firstTime(key) {
+		return
+	}
+	executeHooks(WarnLevel, msg, fields)
+	Logger().Warn(msg, fields...)
+}
+
+// ErrorOnce logs at error level only the first time it is called with key
+func ErrorOnce(key string, msg string, fields ...Field) {
+	if !firstTime(key) {
+		return
+	}
+	executeHooks(ErrorLevel, msg, fields)
+	Logger().Error(msg, fields...)
+}
+
+// ResetOnce forgets key so the next *Once call with it logs again.
+// Mainly useful in tests.
+func ResetOnce(key string) {
+	seenOnce.Delete(key)
+}
+
+// due reports whether interval has elapsed since key was last logged by an
+// *Every function, recording now as its last log time if so
+func due(key string, interval time.Duration) bool {
+	now := time.Now().UnixNano()
+	fresh := new(atomic.Int64)
+	fresh.Store(now)
+	v, loaded := lastEvery.LoadOrStore(key, fresh)
+	if !loaded {
+		return true
+	}
+	last := v.(*atomic.Int64)
+	prev := last.Load()
+	if now-prev < interval.Nanoseconds() {
+		return false
+	}
+	// Only one of several concurrent callers wins the slot
+	return last.CompareAndSwap(prev, now)
+}
+
+// InfoEvery logs at info level at most once per interval for key, dropping
+// the calls in between. It suits periodic status lines such as heartbeats.
+func InfoEvery(interval time.Duration, key, msg string, fields ...Field) {
+	if !due(key, interval) {
+		return
+	}
+	executeHooks(InfoLevel, msg, fields)
+	Logger().Info(msg, fields...)
+}
+
+// ResetEvery forgets key so the next *Every call with it logs immediately.
+// Mainly useful in tests.
+func ResetEvery(key string) {
+	lastEvery.Delete(key)
+}
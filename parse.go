@@ -0,0 +1,105 @@
+package zlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxLogLineSize bounds a single line read by ParseLogFile (stacktraces can be long)
+const maxLogLineSize = 10 * 1024 * 1024
+
+// LogEntry is one JSON log line read back by ParseLogFile
+type LogEntry struct {
+	Time   time.Time
+	Level  Level
+	Msg    string
+	Caller string
+	Fields map[string]interface{} // every other key, including logger and stacktrace
+}
+
+// ParseLogFile reads a file written with Format "json" and returns its entries
+// in order, skipping blank lines. It is meant for integration tests and tools
+// that need to assert on what was logged.
+func ParseLogFile(path string) ([]LogEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []LogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		entry, err := parseLogLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseLogLine decodes a single JSON log line
+func parseLogLine(line []byte) (LogEntry, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return LogEntry{}, err
+	}
+
+	var entry LogEntry
+	if ts, ok := raw["ts"]; ok {
+		t, err := parseLogTime(ts)
+		if err != nil {
+			return LogEntry{}, err
+		}
+		entry.Time = t
+		delete(raw, "ts")
+	}
+	if lvl, ok := raw["level"].(string); ok {
+		if err := entry.Level.UnmarshalText([]byte(lvl)); err != nil {
+			return LogEntry{}, err
+		}
+		delete(raw, "level")
+	}
+	if msg, ok := raw["msg"].(string); ok {
+		entry.Msg = msg
+		delete(raw, "msg")
+	}
+	if caller, ok := raw["caller"].(string); ok {
+		entry.Caller = caller
+		delete(raw, "caller")
+	}
+	entry.Fields = raw
+	return entry, nil
+}
+
+// parseLogTime accepts the ISO8601 strings written by zlog as well as RFC 3339
+// strings and epoch seconds
+func parseLogTime(v interface{}) (time.Time, error) {
+	switch ts := v.(type) {
+	case string:
+		if t, err := time.Parse("2006-01-02T15:04:05.000Z0700", ts); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.RFC3339Nano, ts)
+	case float64:
+		sec := int64(ts)
+		return time.Unix(sec, int64((ts-float64(sec))*1e9)), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported ts value %v", v)
+	}
+}
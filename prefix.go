@@ -0,0 +1,27 @@
+package zlog
+
+import "go.uber.org/zap/zapcore"
+
+// prefixCore prepends "[prefix] " to the message of every entry before the
+// wrapped core checks it, so EntryHooks and every encoder see the prefixed
+// message, and encoders escape it like any other message. LogHooks run
+// before any core and see the message without the prefix. So do the
+// samplers for MessagePrefix, which wrap this core from outside; those of a
+// logger passed to WithPrefix are inside it and see the prefix.
+type prefixCore struct {
+	zapcore.Core
+	prefix string // rendered, with brackets and trailing space
+}
+
+func newPrefixCore(core zapcore.Core, prefix string) zapcore.Core {
+	return &prefixCore{Core: core, prefix: "[" + prefix + "] "}
+}
+
+func (c *prefixCore) With(fields []zapcore.Field) zapcore.Core {
+	return &prefixCore{Core: c.Core.With(fields), prefix: c.prefix}
+}
+
+func (c *prefixCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ent.Message = c.prefix + ent.Message
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,239 @@
+package zlog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldProcessor rewrites an entry and its fields right before they are encoded.
+type fieldProcessor func(ent *zapcore.Entry, fields []Field) []Field
+
+// processorChain is what a processorCore runs. entry runs once per entry that
+// passes the level check, so it applies equally to structured, sugared and
+// context logging, followed by the EntryHooks when entryHooks is set and then
+// by afterHooks. with runs on the fields added by With instead, which the
+// wrapped core encodes once and never passes to Write; it only sees an empty
+// entry.
+type processorChain struct {
+	with       []fieldProcessor
+	entry      []fieldProcessor
+	entryHooks bool
+	afterHooks []fieldProcessor
+}
+
+// processorCore runs a processorChain around the wrapped core
+type processorCore struct {
+	zapcore.Core
+	chain     *processorChain
+	context   []Field // added by With, as processed, for the EntryHooks
+	errOutput zapcore.WriteSyncer
+}
+
+func newProcessorCore(core zapcore.Core, errOutput zapcore.WriteSyncer, chain processorChain) zapcore.Core {
+	return &processorCore{Core: core, chain: &chain, errOutput: errOutput}
+}
+
+func (c *processorCore) With(fields []zapcore.Field) zapcore.Core {
+	var ent zapcore.Entry
+	for _, p := range c.chain.with {
+		fields = p(&ent, fields)
+	}
+	return &processorCore{
+		Core:      c.Core.With(fields),
+		chain:     c.chain,
+		context:   append(c.context[:len(c.context):len(c.context)], fields...),
+		errOutput: c.errOutput,
+	}
+}
+
+func (c *processorCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write processes the entry and hands it to the wrapped core. The wrapped
+// core is checked again so that per-core levels inside a tee still apply.
+func (c *processorCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	for _, p := range c.chain.entry {
+		fields = p(&ent, fields)
+	}
+	if c.chain.entryHooks {
+		executeEntryHooks(&ent, c.context, fields)
+	}
+	for _, p := range c.chain.afterHooks {
+		fields = p(&ent, fields)
+	}
+	if ce := c.Core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = c.errOutput
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+// stacktraceAsArray moves the entry's stacktrace into a []string field with
+// one element per frame, which is far easier to read in JSON log viewers.
+func stacktraceAsArray(ent *zapcore.Entry, fields []Field) []Field {
+	if ent.Stack == "" {
+		return fields
+	}
+	lines := strings.Split(ent.Stack, "\n")
+	frames := make([]string, 0, len(lines)/2+1)
+	for i := 0; i < len(lines); i++ {
+		frame := lines[i]
+		// zap renders each frame as "function\n\tfile:line"
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+			frame += " " + strings.TrimPrefix(lines[i+1], "\t")
+			i++
+		}
+		frames = append(frames, frame)
+	}
+	ent.Stack = ""
+	return append(fields[:len(fields):len(fields)], zap.Strings("stacktrace", frames))
+}
+
+// splitCaller replaces the entry's caller with caller_file and caller_line
+// fields, plus caller_function when withFunction is set
+func splitCaller(withFunction bool) fieldProcessor {
+	return func(ent *zapcore.Entry, fields []Field) []Field {
+		if !ent.Caller.Defined {
+			return fields
+		}
+		file := ent.Caller.TrimmedPath()
+		if i := strings.LastIndexByte(file, ':'); i >= 0 {
+			file = file[:i]
+		}
+		fields = append(fields[:len(fields):len(fields)], String("caller_file", file), Int("caller_line", ent.Caller.Line))
+		if withFunction {
+			fields = append(fields, String("caller_function", ent.Caller.Function))
+		}
+		ent.Caller.Defined = false
+		return fields
+	}
+}
+
+// processStart approximates the process start time; it is taken when the
+// package is initialized
+var processStart = time.Now()
+
+// truncateFields cuts string and byte string fields longer than max bytes,
+// and the message as well when withMessage is set
+func truncateFields(max int, withMessage bool) fieldProcessor {
+	return func(ent *zapcore.Entry, fields []Field) []Field {
+		if withMessage {
+			ent.Message = truncateString(ent.Message, max)
+		}
+		copied := false
+		for i, f := range fields {
+			var t Field
+			switch {
+			case f.Type == zapcore.StringType && len(f.String) > max:
+				t = f
+				t.String = truncateString(f.String, max)
+			case f.Type == zapcore.ByteStringType:
+				b, _ := f.Interface.([]byte)
+				if len(b) <= max {
+					continue
+				}
+				t = zap.ByteString(f.Key, []byte(truncateString(string(b), max)))
+			default:
+				continue
+			}
+			if !copied {
+				fields = append([]Field(nil), fields...)
+				copied = true
+			}
+			fields[i] = t
+		}
+		return fields
+	}
+}
+
+// truncateString cuts s to at most max bytes, on a rune boundary, and notes
+// how much was dropped
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s…(truncated %d bytes)", s[:cut], len(s)-cut)
+}
+
+// uptimeProcessor adds a process_uptime field measured from start
+func uptimeProcessor(start time.Time) fieldProcessor {
+	return func(ent *zapcore.Entry, fields []Field) []Field {
+		return append(fields[:len(fields):len(fields)], Duration("process_uptime", ent.Time.Sub(start)))
+	}
+}
+
+// appendGoroutineID adds a goroutine field with the ID of the logging
+// goroutine. zap writes entries on the caller's goroutine, so this is the
+// goroutine that made the logging call. Reading the ID takes a stack dump,
+// roughly a microsecond per entry.
+func appendGoroutineID(_ *zapcore.Entry, fields []Field) []Field {
+	return append(fields[:len(fields):len(fields)], zap.Uint64("goroutine", goroutineID()))
+}
+
+// sanitizeEntry escapes CR, LF and other control characters in the message
+// and in string fields, so user input cannot forge extra lines in the
+// console output.
+func sanitizeEntry(ent *zapcore.Entry, fields []Field) []Field {
+	ent.Message = sanitizeString(ent.Message)
+	copied := false
+	for i, f := range fields {
+		if f.Type != zapcore.StringType {
+			continue
+		}
+		s := sanitizeString(f.String)
+		if s == f.String {
+			continue
+		}
+		if !copied {
+			fields = append([]Field(nil), fields...)
+			copied = true
+		}
+		fields[i].String = s
+	}
+	return fields
+}
+
+func sanitizeString(s string) string {
+	clean := true
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
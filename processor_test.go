@@ -0,0 +1,114 @@
+package zlog
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// readEntries returns the JSON entries of a log file
+func readEntries(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestMaxFieldBytesAppliesToWith(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.MaxFieldBytes = 8
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("x", 100)
+	l.With(String("ctx", long)).Info("msg", String("field", long))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readEntries(t, cfg.FilePath)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	want := truncateString(long, 8)
+	for _, key := range []string{"ctx", "field"} {
+		if got := entries[0][key]; got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestSanitizeMessagesAppliesToWith(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.SanitizeMessages = true
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.With(String("ctx", "a\nb")).Info("msg", String("field", "a\nb"))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readEntries(t, cfg.FilePath)
+	want := sanitizeString("a\nb")
+	for _, key := range []string{"ctx", "field"} {
+		if got := entries[0][key]; got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+// assertCallerSliceKept logs through l with a slice that has spare capacity
+// and fails if a processor appended into it
+func assertCallerSliceKept(t *testing.T, cfg LoggerConfig, log func(l *ZLogger, fields ...Field)) {
+	t.Helper()
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	base := make([]Field, 1, 4)
+	base[0] = String("k", "v")
+	log(l, base...)
+	if spare := base[:2][1]; spare != (Field{}) {
+		t.Errorf("a processor wrote %v into the caller's slice", spare)
+	}
+}
+
+func TestUptimeKeepsCallerSlice(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.IncludeUptime = true
+	assertCallerSliceKept(t, cfg, func(l *ZLogger, fields ...Field) { l.Info("msg", fields...) })
+}
+
+func TestStacktraceAsArrayKeepsCallerSlice(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.StacktraceAsArray = true
+	assertCallerSliceKept(t, cfg, func(l *ZLogger, fields ...Field) { l.Error("msg", fields...) })
+}
+
+func TestSplitCallerKeepsCallerSlice(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.SplitCaller = true
+	cfg.IncludeFunction = true
+	assertCallerSliceKept(t, cfg, func(l *ZLogger, fields ...Field) { l.Info("msg", fields...) })
+}
+
+func TestGoroutineIDKeepsCallerSlice(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.IncludeGoroutineID = true
+	assertCallerSliceKept(t, cfg, func(l *ZLogger, fields ...Field) { l.Info("msg", fields...) })
+}
@@ -0,0 +1,51 @@
+package zlog
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// rateWatch counts the entries a logger writes, for WarnRateThreshold
+type rateWatch struct {
+	threshold int64
+	count     atomic.Int64
+}
+
+// countEntry is the fieldProcessor that counts written entries
+func (r *rateWatch) countEntry(_ *zapcore.Entry, fields []Field) []Field {
+	r.count.Add(1)
+	return fields
+}
+
+// startRateWatch checks the entry count of r every second until close and
+// logs one warning when it exceeds the threshold. The warning is issued
+// again only after the rate has dropped back below the threshold, so a log
+// storm produces a single meta-warning.
+func (s *loggerState) startRateWatch(r *rateWatch) {
+	s.stopRate = make(chan struct{})
+	logger := s.logger.WithOptions(zap.WithCaller(false))
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		warned := false
+		for {
+			select {
+			case <-s.stopRate:
+				return
+			case <-ticker.C:
+				n := r.count.Swap(0)
+				switch {
+				case n > r.threshold && !warned:
+					warned = true
+					logger.Warn("[zlog] log rate exceeded WarnRateThreshold",
+						Int64("entries_per_second", n), Int64("threshold", r.threshold))
+				case n <= r.threshold:
+					warned = false
+				}
+			}
+		}
+	}()
+}
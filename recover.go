@@ -0,0 +1,50 @@
+package zlog
+
+import (
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// Recover logs a recovered panic at error level together with its stack.
+// recover() only works when called directly by a deferred function, so Recover
+// must itself be deferred:
+//
+//	defer zlog.Recover(zlog.String("worker", name))
+func Recover(fields ...Field) {
+	if r := recover(); r != nil {
+		fields = recoveredFields(r, fields)
+		executeHooks(ErrorLevel, "recovered from panic", fields)
+		Logger().Error("recovered from panic", fields...)
+	}
+}
+
+// RecoverAndRepanic logs a recovered panic like Recover, then panics again
+// with the same value so the crash still propagates.
+func RecoverAndRepanic(fields ...Field) {
+	if r := recover(); r != nil {
+		fields = recoveredFields(r, fields)
+		executeHooks(ErrorLevel, "recovered from panic", fields)
+		Logger().Error("recovered from panic", fields...)
+		panic(r)
+	}
+}
+
+// RecoverAndExit logs a recovered panic like Recover, flushes the logger and
+// exits the process with the given code.
+func RecoverAndExit(code int, fields ...Field) {
+	if r := recover(); r != nil {
+		fields = recoveredFields(r, fields)
+		executeHooks(ErrorLevel, "recovered from panic", fields)
+		Logger().Error("recovered from panic", fields...)
+		_ = Sync()
+		exit(code)
+	}
+}
+
+// recoveredFields appends the recovered value and the panicking goroutine's stack
+func recoveredFields(r interface{}, fields []Field) []Field {
+	out := make([]Field, 0, len(fields)+2)
+	out = append(out, fields...)
+	return append(out, Any("panic", r), zap.ByteString("stack", debug.Stack()))
+}
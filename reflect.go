@@ -0,0 +1,104 @@
+package zlog
+
+import (
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxStructFieldsDepth bounds how deep StructFields descends into nested structs
+const maxStructFieldsDepth = 5
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// StructFields turns the exported fields of struct v (or a pointer to one)
+// into typed fields named prefix.field. A `zlog:"name"` tag renames a field and
+// `zlog:"-"` skips it. Nested structs are flattened up to
+// maxStructFieldsDepth levels; deeper values and types without a typed
+// constructor fall back to Any, and a pointer that leads back into a struct
+// being expanded is logged as "<cycle>". It uses reflection, so prefer
+// explicit fields on hot paths.
+func StructFields(prefix string, v interface{}) []Field {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return []Field{Any(prefix, v)}
+	}
+	return appendStructFields(nil, prefix, rv, 0, map[uintptr]bool{})
+}
+
+// seen holds the addresses of the pointers currently being expanded.
+func appendStructFields(fields []Field, prefix string, rv reflect.Value, depth int, seen map[uintptr]bool) []Field {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("zlog"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		fields = appendValueField(fields, key, rv.Field(i), depth, seen)
+	}
+	return fields
+}
+
+func appendValueField(fields []Field, key string, fv reflect.Value, depth int, seen map[uintptr]bool) []Field {
+	switch fv.Type() {
+	case timeType:
+		return append(fields, Time(key, fv.Interface().(time.Time)))
+	case durationType:
+		return append(fields, Duration(key, time.Duration(fv.Int())))
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return append(fields, String(key, fv.String()))
+	case reflect.Bool:
+		return append(fields, Bool(key, fv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return append(fields, Int64(key, fv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return append(fields, zap.Uint64(key, fv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return append(fields, Float64(key, fv.Float()))
+	case reflect.Struct:
+		if depth+1 < maxStructFieldsDepth {
+			return appendStructFields(fields, key, fv, depth+1, seen)
+		}
+	case reflect.Ptr:
+		if fv.IsNil() {
+			return append(fields, Any(key, nil))
+		}
+		if fv.Elem().Kind() == reflect.Struct && fv.Elem().Type() != timeType && depth+1 < maxStructFieldsDepth {
+			ptr := fv.Pointer()
+			if seen[ptr] {
+				return append(fields, String(key, "<cycle>"))
+			}
+			seen[ptr] = true
+			fields = appendStructFields(fields, key, fv.Elem(), depth+1, seen)
+			delete(seen, ptr)
+			return fields
+		}
+	}
+	return append(fields, Any(key, fv.Interface()))
+}
@@ -0,0 +1,42 @@
+package zlog
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	namedLoggers   = map[string]*ZLogger{}
+	namedLoggersMu sync.RWMutex
+)
+
+// RegisterNamed builds a logger from config and registers it under name, so
+// subsystems can look it up with Named instead of passing loggers around.
+// Its entries carry name in the logger field.
+func RegisterNamed(name string, config LoggerConfig) error {
+	namedLoggersMu.Lock()
+	defer namedLoggersMu.Unlock()
+
+	if _, ok := namedLoggers[name]; ok {
+		return fmt.Errorf("logger %q is already registered", name)
+	}
+	state, err := newLogger(config)
+	if err != nil {
+		return fmt.Errorf("failed to build logger %q: %w", name, err)
+	}
+	namedLoggers[name] = newZLogger(state.logger.Named(name), state)
+	return nil
+}
+
+// Named returns the logger registered under name. If there is none, it
+// returns the global logger with name in its logger field.
+func Named(name string) *ZLogger {
+	namedLoggersMu.RLock()
+	l, ok := namedLoggers[name]
+	namedLoggersMu.RUnlock()
+	if ok {
+		return l
+	}
+	state := currentState()
+	return newZLogger(state.logger.Named(name), state)
+}
@@ -0,0 +1,113 @@
+package zlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Open appendFiles, for Reopen
+var (
+	appendFiles   = make(map[*appendFile]struct{})
+	appendFilesMu sync.Mutex
+)
+
+// appendFile appends to a file without ever rotating it, for setups where an
+// external tool such as logrotate rotates the logs. After the tool has moved
+// the file away, Reopen makes zlog start a new one at the original path.
+type appendFile struct {
+	mu       sync.Mutex
+	filename string
+	file     *os.File
+}
+
+func newAppendFile(filename string) *appendFile {
+	f := &appendFile{filename: filename}
+	appendFilesMu.Lock()
+	appendFiles[f] = struct{}{}
+	appendFilesMu.Unlock()
+	return f
+}
+
+func (f *appendFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	return f.file.Write(p)
+}
+
+// open opens the file for appending. Callers hold mu.
+func (f *appendFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.filename), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(f.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.file = file
+	return nil
+}
+
+// reopen closes the file and opens the file now at the same path
+func (f *appendFile) reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+func (f *appendFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+func (f *appendFile) Close() error {
+	appendFilesMu.Lock()
+	delete(appendFiles, f)
+	appendFilesMu.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Reopen closes and reopens every log file written with Rotate false, so
+// that after logrotate has renamed a file zlog writes to a fresh one at the
+// configured path. Call HandleReopenSignal to run it on SIGHUP, the usual
+// postrotate action. With copytruncate no reopen is needed, since the file
+// is appended to and stays in place.
+func Reopen() error {
+	appendFilesMu.Lock()
+	files := make([]*appendFile, 0, len(appendFiles))
+	for f := range appendFiles {
+		files = append(files, f)
+	}
+	appendFilesMu.Unlock()
+
+	var errs []error
+	for _, f := range files {
+		if err := f.reopen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,357 @@
+package zlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBackupTimeFormat is lumberjack's backup time layout
+	defaultBackupTimeFormat = "2006-01-02T15-04-05.000"
+	defaultBackupSeparator  = "-"
+	compressSuffix          = ".gz"
+	// maxBackupNameSteps bounds the search for a free timestamped backup
+	// name; a second-precision layout needs at most 1000 steps of 1ms
+	maxBackupNameSteps = 2000
+)
+
+// rotator is implemented by the file writers that can rotate on demand
+type rotator interface {
+	Rotate() error
+}
+
+var errNoRotatingFile = errors.New("no rotating file output configured")
+
+// rotate syncs the logger and rotates every file output
+func (s *loggerState) rotate() error {
+	if len(s.rotators) == 0 {
+		return errNoRotatingFile
+	}
+	_ = s.logger.Sync()
+	var errs []error
+	for _, r := range s.rotators {
+		if err := r.Rotate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newRotatingFile returns the size-rotating writer for filename: lumberjack,
+// or a rotatingFile when the backup naming is customized. With Rotate false
+// it returns a plain appendFile instead.
+func newRotatingFile(filename string, cfg LoggerConfig) io.WriteCloser {
+	if cfg.Rotate != nil && !*cfg.Rotate {
+		return newAppendFile(filename)
+	}
+	if cfg.BackupTimeFormat == "" && cfg.BackupSeparator == "" {
+		return newLumberjack(filename, cfg)
+	}
+	loc := time.UTC // lumberjack also names backups in UTC
+	if cfg.RotateLocation != "" {
+		// newLogger has already rejected an unknown zone
+		loc, _ = rotateLocation(cfg.RotateLocation)
+	}
+	f := &rotatingFile{
+		filename:   filename,
+		maxSize:    int64(cfg.MaxSize) * 1024 * 1024,
+		maxBackups: cfg.MaxBackups,
+		maxAge:     time.Duration(cfg.MaxAge) * 24 * time.Hour,
+		compress:   cfg.Compress,
+		timeFormat: cfg.BackupTimeFormat,
+		separator:  cfg.BackupSeparator,
+		loc:        loc,
+	}
+	if f.maxSize <= 0 {
+		f.maxSize = 100 * 1024 * 1024
+	}
+	if f.timeFormat == "" {
+		f.timeFormat = defaultBackupTimeFormat
+	}
+	if f.separator == "" {
+		f.separator = defaultBackupSeparator
+	}
+	return f
+}
+
+// validateBackupNaming checks BackupTimeFormat and BackupSeparator
+func validateBackupNaming(timeFormat, separator string) error {
+	if strings.ContainsAny(separator, `/\`) {
+		return fmt.Errorf("BackupSeparator %q must not contain a path separator", separator)
+	}
+	if timeFormat == "" {
+		return nil
+	}
+	if strings.ContainsAny(timeFormat, `/\`) {
+		return fmt.Errorf("BackupTimeFormat %q must not contain a path separator", timeFormat)
+	}
+	// Backups are found again by parsing their names, so the layout must round-trip
+	t := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	if parsed, err := time.Parse(timeFormat, t.Format(timeFormat)); err != nil || !parsed.Equal(t) {
+		return fmt.Errorf("BackupTimeFormat %q must include the date and time down to the second", timeFormat)
+	}
+	return nil
+}
+
+// rotatingFile is a size-rotating file like lumberjack.Logger, with backups
+// named <name><separator><time><ext> using a configurable time layout.
+// Retention (MaxBackups, MaxAge) and compression follow lumberjack's rules.
+type rotatingFile struct {
+	mu         sync.Mutex
+	filename   string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	timeFormat string
+	separator  string
+	loc        *time.Location
+	file       *os.File
+	size       int64
+
+	millMu sync.Mutex // serializes cleanups of old backups
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if int64(len(p)) > f.maxSize {
+		return 0, fmt.Errorf("write length %d exceeds maximum file size %d", len(p), f.maxSize)
+	}
+	if f.file == nil {
+		if err := f.openExisting(); err != nil {
+			return 0, err
+		}
+	}
+	if f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// openExisting appends to the current file, creating it if needed
+func (f *rotatingFile) openExisting() error {
+	if err := os.MkdirAll(filepath.Dir(f.filename), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(f.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Rotate closes the current file, renames it to a backup and starts a new one
+func (f *rotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+// rotate is Rotate for callers holding mu
+func (f *rotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+	if _, err := os.Stat(f.filename); err == nil {
+		if err := os.Rename(f.filename, f.freeBackupName(time.Now())); err != nil {
+			return fmt.Errorf("failed to rename log file: %w", err)
+		}
+	}
+	if err := f.openExisting(); err != nil {
+		return err
+	}
+	go f.mill()
+	return nil
+}
+
+// backupName returns the backup file name for a rotation at t
+func (f *rotatingFile) backupName(t time.Time) string {
+	dir := filepath.Dir(f.filename)
+	ext := filepath.Ext(f.filename)
+	prefix := strings.TrimSuffix(filepath.Base(f.filename), ext)
+	return filepath.Join(dir, prefix+f.separator+t.In(f.loc).Format(f.timeFormat)+ext)
+}
+
+// freeBackupName returns the backup name for a rotation at t. When that name
+// is taken, as after two rotations within the precision of the time layout,
+// t is moved forward until the name is free, so no backup is overwritten and
+// backups still sort in rotation order. Should that not find a free name,
+// a numbered name such as app-<time>.2.log is used instead.
+func (f *rotatingFile) freeBackupName(t time.Time) string {
+	prev := ""
+	for i := 0; i < maxBackupNameSteps; i++ {
+		name := f.backupName(t.Add(time.Duration(i) * time.Millisecond))
+		if name == prev {
+			continue
+		}
+		if backupNameFree(name) {
+			return name
+		}
+		prev = name
+	}
+	base := f.backupName(t)
+	ext := filepath.Ext(base)
+	for n := 2; ; n++ {
+		name := strings.TrimSuffix(base, ext) + "." + strconv.Itoa(n) + ext
+		if backupNameFree(name) {
+			return name
+		}
+	}
+}
+
+// backupNameFree reports whether neither name nor its compressed form exists
+func backupNameFree(name string) bool {
+	_, err := os.Stat(name)
+	_, gzErr := os.Stat(name + compressSuffix)
+	return os.IsNotExist(err) && os.IsNotExist(gzErr)
+}
+
+// backupFile is an existing backup and the time parsed from its name
+type backupFile struct {
+	path string
+	t    time.Time
+}
+
+// backups lists the existing backups, newest first
+func (f *rotatingFile) backups() ([]backupFile, error) {
+	dir := filepath.Dir(f.filename)
+	ext := filepath.Ext(f.filename)
+	prefix := strings.TrimSuffix(filepath.Base(f.filename), ext) + f.separator
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []backupFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), compressSuffix)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		t, err := time.ParseInLocation(f.timeFormat, ts, f.loc)
+		if err != nil {
+			// A numbered name from freeBackupName
+			if i := strings.LastIndexByte(ts, '.'); i > 0 && isDigits(ts[i+1:]) {
+				t, err = time.ParseInLocation(f.timeFormat, ts[:i], f.loc)
+			}
+			if err != nil {
+				continue
+			}
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, e.Name()), t: t})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].t.After(files[j].t) })
+	return files, nil
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// mill removes backups beyond MaxBackups or older than MaxAge and compresses
+// the rest when Compress is set
+func (f *rotatingFile) mill() {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	files, err := f.backups()
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-f.maxAge)
+	for i, b := range files {
+		if (f.maxBackups > 0 && i >= f.maxBackups) || (f.maxAge > 0 && b.t.Before(cutoff)) {
+			os.Remove(b.path)
+			continue
+		}
+		if f.compress && !strings.HasSuffix(b.path, compressSuffix) {
+			if err := compressFile(b.path); err != nil {
+				fmt.Fprintf(os.Stderr, "[zlog] failed to compress %s: %v\n", b.path, err)
+			}
+		}
+	}
+}
+
+// compressFile gzips path into path.gz and removes path
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if cerr := gz.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + compressSuffix)
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
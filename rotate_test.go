@@ -0,0 +1,85 @@
+package zlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileSameSecondRotations(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.Compress = false
+	cfg.BackupTimeFormat = "2006-01-02T15-04-05"
+	f := newRotatingFile(filepath.Join(dir, "app.log"), cfg).(*rotatingFile)
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 3 {
+		t.Fatalf("got %d backups, want 3", len(backups))
+	}
+	// newest first
+	for i, want := range []string{"third\n", "second\n", "first\n"} {
+		data, err := os.ReadFile(backups[i].path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("backup %d = %q, want %q", i, data, want)
+		}
+	}
+}
+
+func TestNewRejectsUnparsableBackupTimeFormat(t *testing.T) {
+	for _, layout := range []string{"backup", "2006-01-02"} {
+		cfg := testConfig(t)
+		rotate := true
+		cfg.Rotate = &rotate
+		cfg.BackupTimeFormat = layout
+		if l, err := New(cfg); err == nil {
+			l.Close()
+			t.Errorf("New accepted BackupTimeFormat %q", layout)
+		}
+	}
+}
+
+func TestFreeBackupNameFallsBackToNumber(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.BackupTimeFormat = "2006-01-02T15-04-05"
+	f := newRotatingFile(filepath.Join(dir, "app.log"), cfg).(*rotatingFile)
+
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(f.backupName(now.Add(time.Duration(i)*time.Second)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	name := f.freeBackupName(now)
+	if want := filepath.Join(dir, "app-2026-10-15T10-00-00.2.log"); name != want {
+		t.Fatalf("freeBackupName = %q, want %q", name, want)
+	}
+	if err := os.WriteFile(name, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 4 {
+		t.Errorf("got %d backups, want the numbered one counted too", len(backups))
+	}
+}
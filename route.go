@@ -0,0 +1,237 @@
+package zlog
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultRouteMaxOpen     = 64
+	defaultRouteIdleTimeout = 5 * time.Minute
+	routeDefaultValue       = "default"
+)
+
+// RouteConfig splits a file sink into one file per value of a field, e.g. a
+// file per tenant. Every open file holds a file descriptor, so MaxOpen should
+// stay well below the process limit (ulimit -n) minus what the rest of the
+// program needs; files beyond it are closed least recently used first and
+// reopened on demand.
+type RouteConfig struct {
+	Field       string        `yaml:"field"`        // route on this field's value; empty disables routing
+	MaxOpen     int           `yaml:"max_open"`     // files kept open at once; defaults to 64
+	IdleTimeout time.Duration `yaml:"idle_timeout"` // close files unused for this long; defaults to 5m
+}
+
+// routePath returns the file for value: the {route} token in dest replaced
+// by value, or value appended to the file name before its extension.
+// Entries without the field use "default".
+func routePath(dest, value string) string {
+	value = sanitizeRouteValue(value)
+	if strings.Contains(dest, "{route}") {
+		return strings.ReplaceAll(dest, "{route}", value)
+	}
+	ext := filepath.Ext(dest)
+	return strings.TrimSuffix(dest, ext) + "-" + value + ext
+}
+
+// sanitizeRouteValue keeps a field value from escaping the log directory
+func sanitizeRouteValue(value string) string {
+	if value == "" {
+		return routeDefaultValue
+	}
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, value)
+	if strings.Trim(clean, ".") == "" {
+		return strings.Repeat("_", len(clean))
+	}
+	return clean
+}
+
+// routeValue returns the value of field key as a string
+func routeValue(f Field) string {
+	if f.Type == zapcore.StringType {
+		return f.String
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return fmt.Sprint(enc.Fields[f.Key])
+}
+
+// routingCore writes each entry to the file of its route field's value
+type routingCore struct {
+	zapcore.LevelEnabler
+	enc   zapcore.Encoder
+	key   string
+	value string // route value set through With, if any
+	pool  *routePool
+}
+
+func (c *routingCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &routingCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), key: c.key, value: c.value, pool: c.pool}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+		if f.Key == c.key {
+			clone.value = routeValue(f)
+		}
+	}
+	return clone
+}
+
+func (c *routingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *routingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	value := c.value
+	for _, f := range fields {
+		if f.Key == c.key {
+			value = routeValue(f)
+		}
+	}
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	return c.pool.write(value, buf.Bytes())
+}
+
+func (c *routingCore) Sync() error {
+	return nil // the rotating files write straight to disk
+}
+
+// routePool is an LRU of open rotating files, one per route value
+type routePool struct {
+	mu          sync.Mutex
+	dest        string
+	cfg         LoggerConfig
+	maxOpen     int
+	idleTimeout time.Duration
+	lru         *list.List // of *routeFile, most recently used first
+	files       map[string]*list.Element
+	done        chan struct{}
+	wg          sync.WaitGroup
+	closeOnce   sync.Once
+}
+
+type routeFile struct {
+	value    string
+	writer   io.WriteCloser
+	lastUsed time.Time
+}
+
+func newRoutePool(dest string, cfg LoggerConfig, rc RouteConfig) *routePool {
+	p := &routePool{
+		dest:        dest,
+		cfg:         cfg,
+		maxOpen:     rc.MaxOpen,
+		idleTimeout: rc.IdleTimeout,
+		lru:         list.New(),
+		files:       make(map[string]*list.Element),
+		done:        make(chan struct{}),
+	}
+	if p.maxOpen <= 0 {
+		p.maxOpen = defaultRouteMaxOpen
+	}
+	if p.idleTimeout <= 0 {
+		p.idleTimeout = defaultRouteIdleTimeout
+	}
+	p.wg.Add(1)
+	go p.closeIdleLoop()
+	return p
+}
+
+func (p *routePool) write(value string, b []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	el, ok := p.files[value]
+	if ok {
+		p.lru.MoveToFront(el)
+	} else {
+		path, err := prepareLogFile(routePath(p.dest, value))
+		if err != nil {
+			return err
+		}
+		el = p.lru.PushFront(&routeFile{value: value, writer: newRotatingFile(path, p.cfg)})
+		p.files[value] = el
+		for p.lru.Len() > p.maxOpen {
+			p.remove(p.lru.Back())
+		}
+	}
+	rf := el.Value.(*routeFile)
+	rf.lastUsed = time.Now()
+	_, err := rf.writer.Write(b)
+	return err
+}
+
+// remove closes the file of el. Callers hold mu.
+func (p *routePool) remove(el *list.Element) {
+	rf := p.lru.Remove(el).(*routeFile)
+	delete(p.files, rf.value)
+	rf.writer.Close()
+}
+
+func (p *routePool) closeIdleLoop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.idleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			p.mu.Lock()
+			for el := p.lru.Back(); el != nil; el = p.lru.Back() {
+				if now.Sub(el.Value.(*routeFile).lastUsed) < p.idleTimeout {
+					break
+				}
+				p.remove(el)
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Rotate rotates every open file
+func (p *routePool) Rotate() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for el := p.lru.Front(); el != nil; el = el.Next() {
+		if r, ok := el.Value.(*routeFile).writer.(rotator); ok {
+			if err := r.Rotate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops the idle check and closes every open file. Closing again is a no-op.
+func (p *routePool) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for el := p.lru.Back(); el != nil; el = p.lru.Back() {
+		p.remove(el)
+	}
+	return nil
+}
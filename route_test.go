@@ -0,0 +1,71 @@
+package zlog
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// openRoutes returns the route values with an open file
+func openRoutes(p *routePool) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	values := make([]string, 0, len(p.files))
+	for value := range p.files {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+func TestRoutePoolEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.Compress = false
+	p := newRoutePool(filepath.Join(dir, "app.log"), cfg, RouteConfig{MaxOpen: 2})
+	defer p.Close()
+
+	for _, value := range []string{"a", "b", "a", "c"} {
+		if err := p.write(value, []byte(value+"\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := openRoutes(p); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Fatalf("open routes = %q, want [a c]", got)
+	}
+
+	// An evicted route is reopened and appended to
+	if err := p.write("b", []byte("b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := openRoutes(p); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Fatalf("open routes = %q, want [b c]", got)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "app-b.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "b\nb\n" {
+		t.Errorf("app-b.log = %q, want both entries", data)
+	}
+}
+
+func TestRoutePoolClosesIdleFiles(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Compress = false
+	p := newRoutePool(filepath.Join(t.TempDir(), "app.log"), cfg, RouteConfig{IdleTimeout: 20 * time.Millisecond})
+	defer p.Close()
+
+	if err := p.write("a", []byte("a\n")); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(openRoutes(p)) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("open routes = %q, want the idle file closed", openRoutes(p))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,221 @@
+package zlog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	samplingTick       = time.Second
+	samplingFirst      = 100
+	samplingThereafter = 100
+	maxSampledKeys     = 4096 // distinct messages counted per window before they share a counter
+)
+
+// Sampling decisions of every zlog logger, for SamplingStats
+var (
+	samplingKept    atomic.Uint64
+	samplingDropped atomic.Uint64
+)
+
+// SamplingStats returns how many entries the samplers have let through and
+// how many they have dropped since start or the last ResetSamplingStats,
+// across all loggers. Levels exempt from sampling are not counted.
+func SamplingStats() (sampled, dropped uint64) {
+	return samplingKept.Load(), samplingDropped.Load()
+}
+
+// ResetSamplingStats sets the SamplingStats counters back to zero
+func ResetSamplingStats() {
+	samplingKept.Store(0)
+	samplingDropped.Store(0)
+}
+
+var (
+	samplingCallbacks      atomic.Pointer[[]func(zapcore.Entry, zapcore.SamplingDecision)]
+	samplingCallbacksMutex sync.Mutex
+)
+
+// OnSamplingDecision registers fn to be called with every entry the samplers
+// of any zlog logger keep (zapcore.LogSampled) or drop (zapcore.LogDropped),
+// e.g. to count drops in a metric. fn runs on the logging path, so it must
+// be fast and must not log.
+func OnSamplingDecision(fn func(entry zapcore.Entry, decision zapcore.SamplingDecision)) {
+	if fn == nil {
+		return
+	}
+	samplingCallbacksMutex.Lock()
+	defer samplingCallbacksMutex.Unlock()
+	var callbacks []func(zapcore.Entry, zapcore.SamplingDecision)
+	if old := samplingCallbacks.Load(); old != nil {
+		callbacks = append(callbacks, *old...)
+	}
+	callbacks = append(callbacks, fn)
+	samplingCallbacks.Store(&callbacks)
+}
+
+// recordSamplingDecision is the sampler hook shared by zap's sampler and keyedSampler
+func recordSamplingDecision(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+	if dec&zapcore.LogDropped != 0 {
+		samplingDropped.Add(1)
+	} else {
+		samplingKept.Add(1)
+	}
+	if callbacks := samplingCallbacks.Load(); callbacks != nil {
+		for _, fn := range *callbacks {
+			fn(ent, dec)
+		}
+	}
+}
+
+// samplerKey identifies a distinct message at a given level.
+type samplerKey struct {
+	level zapcore.Level
+	msg   string
+}
+
+// keyedCounts holds the per-key counters of the current sampling window.
+// It is shared by every core derived through With.
+type keyedCounts struct {
+	mu       sync.Mutex
+	resetAt  int64
+	counts   map[samplerKey]uint64
+	overflow uint64 // shared by the keys beyond maxSampledKeys
+}
+
+// inc increments the counter for key and returns its new value, starting a
+// fresh window (and dropping every old key) once the tick has elapsed. Once
+// the window holds maxSampledKeys keys, new keys share a single counter, so
+// messages with unbounded content cannot grow the map without limit.
+func (kc *keyedCounts) inc(key samplerKey, t time.Time, tick time.Duration) uint64 {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	tn := t.UnixNano()
+	if tn >= kc.resetAt {
+		kc.counts = make(map[samplerKey]uint64, len(kc.counts))
+		kc.overflow = 0
+		kc.resetAt = tn + tick.Nanoseconds()
+	}
+	if _, ok := kc.counts[key]; !ok && len(kc.counts) >= maxSampledKeys {
+		kc.overflow++
+		return kc.overflow
+	}
+	kc.counts[key]++
+	return kc.counts[key]
+}
+
+// keyedSampler samples entries per exact level+message key. Unlike zap's
+// sampler, which hashes messages into a fixed number of buckets, distinct
+// messages never share a counter, so a rare line always gets its first N
+// entries even when a chatty one saturates the window. Past maxSampledKeys
+// distinct messages in a window, the rest are sampled together.
+type keyedSampler struct {
+	zapcore.Core
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	counts     *keyedCounts
+	hook       func(zapcore.Entry, zapcore.SamplingDecision)
+}
+
+func newKeyedSampler(core zapcore.Core, tick time.Duration, first, thereafter int, hook func(zapcore.Entry, zapcore.SamplingDecision)) zapcore.Core {
+	return &keyedSampler{
+		Core:       core,
+		tick:       tick,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		counts:     &keyedCounts{},
+		hook:       hook,
+	}
+}
+
+func (s *keyedSampler) With(fields []zapcore.Field) zapcore.Core {
+	return &keyedSampler{
+		Core:       s.Core.With(fields),
+		tick:       s.tick,
+		first:      s.first,
+		thereafter: s.thereafter,
+		counts:     s.counts,
+		hook:       s.hook,
+	}
+}
+
+func (s *keyedSampler) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !s.Enabled(ent.Level) {
+		return ce
+	}
+
+	n := s.counts.inc(samplerKey{level: ent.Level, msg: ent.Message}, ent.Time, s.tick)
+	if n > s.first && (s.thereafter == 0 || (n-s.first)%s.thereafter != 0) {
+		s.hook(ent, zapcore.LogDropped)
+		return ce
+	}
+	s.hook(ent, zapcore.LogSampled)
+	return s.Core.Check(ent, ce)
+}
+
+// sampledLevels returns the filter selecting the levels to sample: those in
+// SamplingLevels when set, otherwise those below SamplingLevelFloor
+func sampledLevels(cfg LoggerConfig) func(zapcore.Level) bool {
+	if len(cfg.SamplingLevels) > 0 {
+		set := make(map[zapcore.Level]bool, len(cfg.SamplingLevels))
+		for _, lvl := range cfg.SamplingLevels {
+			if lvl.Valid() {
+				set[lvl.toZapCoreLevel()] = true
+			}
+		}
+		return func(l zapcore.Level) bool { return set[l] }
+	}
+	floor := cfg.SamplingLevelFloor
+	if !floor.Valid() {
+		floor = ErrorLevel
+	}
+	return func(l zapcore.Level) bool { return l < floor.toZapCoreLevel() }
+}
+
+// levelSplitCore routes entries whose level satisfies sample through the
+// sampled core and every other entry straight to the raw core, so that
+// sampling never drops the levels it exempts.
+type levelSplitCore struct {
+	sampled zapcore.Core
+	raw     zapcore.Core
+	sample  func(zapcore.Level) bool
+}
+
+func newLevelSplitCore(sampled, raw zapcore.Core, sample func(zapcore.Level) bool) zapcore.Core {
+	return &levelSplitCore{sampled: sampled, raw: raw, sample: sample}
+}
+
+func (c *levelSplitCore) Enabled(lvl zapcore.Level) bool {
+	return c.raw.Enabled(lvl)
+}
+
+// With normalizes fields once, so Lazy fields are not resolved by both cores
+func (c *levelSplitCore) With(fields []zapcore.Field) zapcore.Core {
+	fields = toZapFields(nil, fields)
+	return &levelSplitCore{
+		sampled: c.sampled.With(fields),
+		raw:     c.raw.With(fields),
+		sample:  c.sample,
+	}
+}
+
+func (c *levelSplitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.sample(ent.Level) {
+		return c.sampled.Check(ent, ce)
+	}
+	return c.raw.Check(ent, ce)
+}
+
+// Write is only reached if a caller skips Check; it bypasses sampling
+func (c *levelSplitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.raw.Write(ent, fields)
+}
+
+func (c *levelSplitCore) Sync() error {
+	return c.raw.Sync()
+}
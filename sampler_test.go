@@ -0,0 +1,54 @@
+package zlog
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSamplingWithResolvesLazyOnce(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Sampling = true
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	calls := 0
+	l.With(Lazy("k", func() interface{} {
+		calls++
+		return "v"
+	})).Info("msg")
+	if calls != 1 {
+		t.Errorf("lazy func called %d times, want 1", calls)
+	}
+}
+
+func TestKeyedCountsCapsKeysPerWindow(t *testing.T) {
+	kc := &keyedCounts{}
+	now := time.Now()
+	for i := 0; i < maxSampledKeys+10; i++ {
+		kc.inc(samplerKey{level: zapcore.InfoLevel, msg: strconv.Itoa(i)}, now, time.Second)
+	}
+	if len(kc.counts) != maxSampledKeys {
+		t.Errorf("window holds %d keys, want %d", len(kc.counts), maxSampledKeys)
+	}
+	// Keys past the cap share a counter; keys already seen keep their own
+	if got := kc.inc(samplerKey{level: zapcore.InfoLevel, msg: "new"}, now, time.Second); got != 11 {
+		t.Errorf("overflow count = %d, want 11", got)
+	}
+	if got := kc.inc(samplerKey{level: zapcore.InfoLevel, msg: "0"}, now, time.Second); got != 2 {
+		t.Errorf("count of a known key = %d, want 2", got)
+	}
+
+	// The next window starts over
+	if got := kc.inc(samplerKey{level: zapcore.InfoLevel, msg: "new"}, now.Add(time.Second), time.Second); got != 1 {
+		t.Errorf("count in a new window = %d, want 1", got)
+	}
+	if kc.overflow != 0 {
+		t.Errorf("overflow = %d in a new window, want 0", kc.overflow)
+	}
+}
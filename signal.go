@@ -0,0 +1,60 @@
+package zlog
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var handleSignalsOnce sync.Once
+
+// HandleSignals flushes and closes the global logger when one of signals
+// (SIGINT and SIGTERM by default) arrives, then re-raises the signal with its
+// default behavior so the process terminates as it would have without zlog.
+// Only the first call has an effect; it starts a single goroutine.
+func HandleSignals(signals ...os.Signal) {
+	handleSignalsOnce.Do(func() {
+		if len(signals) == 0 {
+			signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+		}
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, signals...)
+		go func() {
+			sig := <-ch
+			signal.Stop(ch)
+			_ = Close()
+
+			signal.Reset(signals...)
+			p, err := os.FindProcess(os.Getpid())
+			if err == nil {
+				err = p.Signal(sig)
+			}
+			if err != nil {
+				// Re-raising is not supported everywhere (e.g. Windows)
+				os.Exit(1)
+			}
+		}()
+	})
+}
+
+var handleReopenSignalOnce sync.Once
+
+// HandleReopenSignal calls Reopen whenever SIGHUP arrives, so files written
+// with Rotate false follow logrotate's move-then-signal rotation. The signal
+// no longer terminates the process. Only the first call has an effect; it
+// starts a single goroutine.
+func HandleReopenSignal() {
+	handleReopenSignalOnce.Do(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGHUP)
+		go func() {
+			for range ch {
+				if err := Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "[zlog] failed to reopen log files: %v\n", err)
+				}
+			}
+		}()
+	})
+}
@@ -0,0 +1,288 @@
+package zlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkConfig describes one log destination. A LoggerConfig with Sinks set
+// writes to exactly those sinks and ignores Output, ConsoleStream,
+// ConsoleLevel and FileLevel. Several sinks may share a stream, e.g. a
+// colored console sink and a json sink both on stdout for tooling that
+// parses the output; each entry is then written once per sink, and the
+// lines of different sinks never interleave.
+type SinkConfig struct {
+	Destination string `yaml:"destination"` // stdout、stderr、eventlog、tcp://host:port、udp://host:port, or a file path
+	Format      string `yaml:"format"`      // json、json-pretty、console、gelf, or a RegisterEncoder name
+	Level       Level  `yaml:"level"`       // defaults to LoggerConfig.Level
+	Color       bool   `yaml:"color"`       // colored levels, console format only
+
+	Network NetworkConfig `yaml:"network"` // tcp:// and udp:// destinations only
+	Route   RouteConfig   `yaml:"route"`   // file destinations only
+}
+
+// isConsoleDestination reports whether dest names a standard stream
+func isConsoleDestination(dest string) bool {
+	return dest == "stdout" || dest == "stderr"
+}
+
+// legacySinks translates the Output/Format/ConsoleStream settings into sinks
+func legacySinks(cfg LoggerConfig) []SinkConfig {
+	var sinks []SinkConfig
+	if cfg.Output == "console" || cfg.Output == "both" {
+		stream := "stdout"
+		if cfg.ConsoleStream == "stderr" {
+			stream = "stderr"
+		}
+		format := cfg.Format
+		if cfg.ConsoleFormat != "" {
+			format = cfg.ConsoleFormat
+		}
+		sinks = append(sinks, SinkConfig{
+			Destination: stream,
+			Format:      format,
+			Level:       cfg.ConsoleLevel,
+			Color:       true, // only applies to the console format
+		})
+	}
+	if cfg.Output == "eventlog" {
+		sinks = append(sinks, SinkConfig{Destination: eventLogDestination, Format: cfg.Format})
+	}
+	if cfg.Output == "file" || cfg.Output == "both" {
+		format := cfg.Format
+		if cfg.FileFormat != "" {
+			format = cfg.FileFormat
+		}
+		if format == "json-pretty" {
+			// Indented entries only suit a terminal; files stay one entry per line
+			format = "json"
+		}
+		sinks = append(sinks, SinkConfig{
+			Destination: cfg.FilePath,
+			Format:      format,
+			Level:       cfg.FileLevel,
+		})
+	}
+	return sinks
+}
+
+// sinkBuilder builds the cores of one logger, collecting the closers of the
+// writers it opens
+type sinkBuilder struct {
+	cfg           LoggerConfig
+	encoderConfig zapcore.EncoderConfig
+	level         zap.AtomicLevel
+	errOutput     zapcore.WriteSyncer
+	closers       []func() error
+	consoles      []*consoleSwitch
+	rotators      []rotator
+	streams       map[string]zapcore.WriteSyncer // stdout/stderr, shared by the sinks writing to them
+	files         map[string]zapcore.WriteSyncer // file outputs by path, shared likewise
+}
+
+// close releases the outputs built so far, when building the logger fails
+func (b *sinkBuilder) close() {
+	for _, c := range b.closers {
+		_ = c()
+	}
+}
+
+// build returns the core writing to sink s
+func (b *sinkBuilder) build(s SinkConfig) (zapcore.Core, error) {
+	if s.Destination == "" {
+		return nil, fmt.Errorf("sink destination is required")
+	}
+	if s.Level != "" && !s.Level.Valid() {
+		s.Level = ""
+	}
+	enc, err := b.encoder(s)
+	if err != nil {
+		return nil, err
+	}
+	if s.Route.Field != "" {
+		return b.routingCore(s, enc)
+	}
+	if s.Destination == eventLogDestination {
+		return b.eventLogCore(s, enc)
+	}
+	ws, err := b.writer(s)
+	if err != nil {
+		return nil, err
+	}
+	core := zapcore.NewCore(enc, ws, coreLevel(s.Level, b.level))
+	if isConsoleDestination(s.Destination) {
+		sw := newConsoleSwitch(b, s, ws, core)
+		b.consoles = append(b.consoles, sw)
+		return &switchableCore{sw: sw}, nil
+	}
+	return core, nil
+}
+
+// encoder returns the encoder for s, falling back to console for unknown formats
+func (b *sinkBuilder) encoder(s SinkConfig) (zapcore.Encoder, error) {
+	encCfg := b.encoderConfig
+	constructor, ok := lookupEncoder(s.Format)
+	if !ok {
+		s.Format = "console"
+		constructor, _ = lookupEncoder(s.Format)
+	}
+	if b.cfg.LevelEncoder != "" {
+		encCfg.EncodeLevel = levelEncoder(b.cfg.LevelEncoder, b.cfg.LevelColors)
+	} else if s.Color && s.Format == "console" {
+		encCfg.EncodeLevel = colorLevelEncoder(b.cfg.LevelColors, true)
+	}
+	enc, err := constructor(encCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %q encoder: %w", s.Format, err)
+	}
+	return enc, nil
+}
+
+// routingCore returns the core splitting file sink s by its route field
+func (b *sinkBuilder) routingCore(s SinkConfig, enc zapcore.Encoder) (zapcore.Core, error) {
+	if isConsoleDestination(s.Destination) || isNetworkDestination(s.Destination) {
+		return nil, fmt.Errorf("sink %q: routing requires a file destination", s.Destination)
+	}
+	dest, err := filepath.Abs(s.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %w", s.Destination, err)
+	}
+	pool := newRoutePool(dest, b.cfg, s.Route)
+	b.closers = append(b.closers, pool.Close)
+	b.rotators = append(b.rotators, pool)
+	return &routingCore{
+		LevelEnabler: coreLevel(s.Level, b.level),
+		enc:          enc,
+		key:          s.Route.Field,
+		pool:         pool,
+	}, nil
+}
+
+// eventLogCore returns the core writing to the Windows Event Log
+func (b *sinkBuilder) eventLogCore(s SinkConfig, enc zapcore.Encoder) (zapcore.Core, error) {
+	source := b.cfg.EventLogSource
+	if source == "" {
+		source = defaultEventLogSource
+	}
+	log, err := openEventLog(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log %q: %w", source, err)
+	}
+	b.closers = append(b.closers, log.Close)
+	return &eventLogCore{LevelEnabler: coreLevel(s.Level, b.level), enc: enc, log: log}, nil
+}
+
+// stream returns the locked WriteSyncer for stdout or stderr. Sinks on the
+// same stream share it, so their writes are serialized.
+func (b *sinkBuilder) stream(dest string) zapcore.WriteSyncer {
+	if ws, ok := b.streams[dest]; ok {
+		return ws
+	}
+	f := os.Stdout
+	if dest == "stderr" {
+		f = os.Stderr
+	}
+	ws := zapcore.Lock(f)
+	if b.streams == nil {
+		b.streams = make(map[string]zapcore.WriteSyncer)
+	}
+	b.streams[dest] = ws
+	return ws
+}
+
+// writer opens the WriteSyncer for s
+func (b *sinkBuilder) writer(s SinkConfig) (zapcore.WriteSyncer, error) {
+	dest := s.Destination
+	if isConsoleDestination(dest) {
+		return b.stream(dest), nil
+	}
+	if isNetworkDestination(dest) {
+		nw, err := newNetworkWriter(dest, s.Network)
+		if err != nil {
+			return nil, err
+		}
+		b.closers = append(b.closers, nw.Close)
+		return nw, nil
+	}
+
+	path, err := prepareLogFile(dest)
+	if err != nil {
+		return nil, err
+	}
+	if ws, ok := b.files[path]; ok {
+		return ws, nil
+	}
+	if err := checkLogFile(path); err != nil {
+		return nil, err
+	}
+	fileCfg := b.cfg
+	fileCfg.FilePath = path
+	if dest != b.cfg.FilePath {
+		// FilenameTemplate only names the file written for FilePath
+		fileCfg.FilenameTemplate = ""
+	}
+	writer, err := newFileWriter(fileCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var ws zapcore.WriteSyncer = zapcore.AddSync(writer)
+	if b.cfg.FileErrorFallback {
+		ws = newFallbackWriteSyncer(ws, b.errOutput)
+	}
+	if b.cfg.BufferSize > 0 {
+		buffered := &zapcore.BufferedWriteSyncer{
+			WS:            ws,
+			Size:          b.cfg.BufferSize,
+			FlushInterval: b.cfg.FlushInterval,
+		}
+		b.closers = append(b.closers, buffered.Stop)
+		ws = buffered
+	}
+	b.closers = append(b.closers, writer.Close)
+	if r, ok := writer.(rotator); ok {
+		b.rotators = append(b.rotators, r)
+	}
+	if b.files == nil {
+		b.files = make(map[string]zapcore.WriteSyncer)
+	}
+	b.files[path] = ws
+	return ws, nil
+}
+
+// prepareLogFile resolves path against the working directory and creates its directory
+func prepareLogFile(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+		path = filepath.Join(wd, path)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create log directory %q: %w", dir, err)
+	}
+	return path, nil
+}
+
+// checkLogFile reports a path that is a directory, or a directory the
+// process cannot create files in, which the rotating writer would otherwise
+// only fail on at the first write
+func checkLogFile(path string) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("log file %q is a directory", path)
+	}
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, ".zlog-write-check-*")
+	if err != nil {
+		return fmt.Errorf("log directory %q is not writable: %w", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
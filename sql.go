@@ -0,0 +1,143 @@
+package zlog
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	maxSQLQueryBytes = 2048
+	maxSQLArgs       = 20
+	maxSQLArgBytes   = 256
+	sqlRedacted      = "[REDACTED]"
+)
+
+// sqlSecretWords mark a column or argument name whose value must not be logged
+var sqlSecretWords = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "credential", "auth"}
+
+// SQLField returns a "sql" object field holding query and args, safe to log
+// from a DB layer:
+//   - the query is cut to 2 KiB and at most 20 args are kept, with args_count
+//     giving the real number;
+//   - string and byte args are cut to 256 bytes;
+//   - an arg is replaced by [REDACTED] when it is a sql.NamedArg with a
+//     secret-looking name (password, token, ...) or when its placeholder
+//     (?, $n) directly follows such a column, as in "password = ?".
+//
+// Redaction is a heuristic for obvious cases, not a guarantee; it does not
+// see through INSERT column lists, for instance.
+func SQLField(query string, args ...interface{}) Field {
+	secret := sqlSecretPositions(query)
+	return zap.Object("sql", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("query", truncateString(query, maxSQLQueryBytes))
+		enc.AddInt("args_count", len(args))
+		return enc.AddArray("args", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for i, arg := range args {
+				if i == maxSQLArgs {
+					break
+				}
+				if named, ok := arg.(sql.NamedArg); ok {
+					if isSQLSecret(named.Name) {
+						arr.AppendString(sqlRedacted)
+						continue
+					}
+					arg = named.Value
+				}
+				if secret[i] {
+					arr.AppendString(sqlRedacted)
+					continue
+				}
+				appendSQLArg(arr, arg)
+			}
+			return nil
+		}))
+	}))
+}
+
+func appendSQLArg(arr zapcore.ArrayEncoder, arg interface{}) {
+	switch v := arg.(type) {
+	case nil:
+		arr.AppendString("NULL")
+	case string:
+		arr.AppendString(truncateString(v, maxSQLArgBytes))
+	case []byte:
+		arr.AppendString(truncateString(string(v), maxSQLArgBytes))
+	default:
+		if err := arr.AppendReflected(v); err != nil {
+			arr.AppendString("<unloggable>")
+		}
+	}
+}
+
+func isSQLSecret(name string) bool {
+	name = strings.ToLower(name)
+	for _, w := range sqlSecretWords {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlSecretPositions returns the indexes of the args whose placeholder, ?
+// or $n, follows a secret-looking column in a comparison or assignment.
+// Placeholders inside quoted strings are ignored.
+func sqlSecretPositions(query string) map[int]bool {
+	var secret map[int]bool
+	mark := func(i int, before string) {
+		if !isSQLSecret(lastSQLIdentifier(before)) {
+			return
+		}
+		if secret == nil {
+			secret = make(map[int]bool)
+		}
+		secret[i] = true
+	}
+	next := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			mark(next, query[:i])
+			next++
+		case c == '$':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if n, err := strconv.Atoi(query[i+1 : j]); err == nil && n > 0 {
+				mark(n-1, query[:i])
+				i = j - 1
+			}
+		}
+	}
+	return secret
+}
+
+// lastSQLIdentifier returns the identifier before the comparison operator at
+// the end of s, e.g. "password" for "UPDATE users SET password ="
+func lastSQLIdentifier(s string) string {
+	s = strings.TrimRight(s, " \t\r\n=<>!")
+	end := len(s)
+	start := end
+	for start > 0 {
+		c := s[start-1]
+		if c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			start--
+			continue
+		}
+		break
+	}
+	return s[start:end]
+}
@@ -0,0 +1,43 @@
+package zlog
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSQLSecretPositions(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  map[int]bool
+	}{
+		{"SELECT * FROM users WHERE id = ?", nil},
+		{"SELECT * FROM users WHERE name = ? AND password = ?", map[int]bool{1: true}},
+		{"UPDATE users SET api_key=?, name=? WHERE id=?", map[int]bool{0: true}},
+		{"SELECT * FROM t WHERE u.auth_token <> $2 AND id = $1", map[int]bool{1: true}},
+		{"SELECT * FROM t WHERE note = 'password = ?' AND id = ?", nil},                // quoted placeholders are not args
+		{`SELECT * FROM t WHERE "secret" = 'x?' AND token = ?`, map[int]bool{0: true}}, // nor do they shift the count
+		{"INSERT INTO users (name, password) VALUES (?, ?)", nil},                      // column lists are not seen through
+	} {
+		if got := sqlSecretPositions(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sqlSecretPositions(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSQLFieldRedactsSecrets(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	SQLField("SELECT * FROM users WHERE name = ? AND password = ? AND id = ?",
+		"alice", "hunter2", sql.Named("Token", "abc")).AddTo(enc)
+
+	got := enc.Fields["sql"].(map[string]interface{})
+	want := []interface{}{"alice", sqlRedacted, sqlRedacted}
+	if args := got["args"]; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+	if got["args_count"] != 3 {
+		t.Errorf("args_count = %v, want 3", got["args_count"])
+	}
+}
@@ -0,0 +1,55 @@
+package zlog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync/atomic"
+)
+
+var sugarStrict atomic.Bool
+
+// SetSugarStrict turns validation of the keysAndValues passed to the *w
+// functions on or off. When on, an odd number of arguments (a key without a
+// value) or a non-string key is reported on stderr with the offending caller.
+// It is off by default.
+func SetSugarStrict(strict bool) {
+	sugarStrict.Store(strict)
+}
+
+// checkKeysAndValues reports malformed keysAndValues when strict mode is on.
+// It must be called directly by the exported *w function so that the reported
+// caller is the user's code.
+func checkKeysAndValues(keysAndValues []interface{}) {
+	if !sugarStrict.Load() {
+		return
+	}
+	problem := keysAndValuesProblem(keysAndValues)
+	if problem == "" {
+		return
+	}
+	caller := "unknown caller"
+	if _, file, line, ok := runtime.Caller(2); ok {
+		caller = fmt.Sprintf("%s:%d", file, line)
+	}
+	fmt.Fprintf(os.Stderr, "[zlog] malformed keysAndValues at %s: %s\n", caller, problem)
+}
+
+// keysAndValuesProblem describes what is wrong with keysAndValues, or returns
+// "" if they are well formed. Like zap's sugar, a Field counts as a complete pair.
+func keysAndValuesProblem(keysAndValues []interface{}) string {
+	for i := 0; i < len(keysAndValues); {
+		if _, ok := keysAndValues[i].(Field); ok {
+			i++
+			continue
+		}
+		if i == len(keysAndValues)-1 {
+			return fmt.Sprintf("key %v has no value", keysAndValues[i])
+		}
+		if _, ok := keysAndValues[i].(string); !ok {
+			return fmt.Sprintf("non-string key %v (%T) at position %d", keysAndValues[i], keysAndValues[i], i)
+		}
+		i += 2
+	}
+	return ""
+}
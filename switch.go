@@ -0,0 +1,134 @@
+package zlog
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// consoleSwitch holds the current core of a stdout/stderr sink, so its
+// encoder can be replaced at runtime without touching the other sinks
+type consoleSwitch struct {
+	mu      sync.Mutex // serializes swaps
+	builder *sinkBuilder
+	sink    SinkConfig
+	ws      zapcore.WriteSyncer
+	current atomic.Pointer[switchState]
+}
+
+// switchState is one generation of a consoleSwitch's core
+type switchState struct {
+	gen  uint64
+	core zapcore.Core
+}
+
+func newConsoleSwitch(b *sinkBuilder, s SinkConfig, ws zapcore.WriteSyncer, core zapcore.Core) *consoleSwitch {
+	sw := &consoleSwitch{builder: b, sink: s, ws: ws}
+	sw.current.Store(&switchState{core: core})
+	return sw
+}
+
+// setFormat rebuilds the core with the encoder registered as format
+func (sw *consoleSwitch) setFormat(format string) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	s := sw.sink
+	s.Format = format
+	if err := sw.rebuild(s, sw.ws); err != nil {
+		return err
+	}
+	sw.sink = s
+	return nil
+}
+
+// setWriter rebuilds the core to write to ws and returns the previous writer
+func (sw *consoleSwitch) setWriter(ws zapcore.WriteSyncer) (zapcore.WriteSyncer, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if err := sw.rebuild(sw.sink, ws); err != nil {
+		return nil, err
+	}
+	prev := sw.ws
+	sw.ws = ws
+	return prev, nil
+}
+
+// rebuild installs a new core for s writing to ws. Callers hold mu.
+func (sw *consoleSwitch) rebuild(s SinkConfig, ws zapcore.WriteSyncer) error {
+	enc, err := sw.builder.encoder(s)
+	if err != nil {
+		return err
+	}
+	sw.current.Store(&switchState{
+		gen:  sw.current.Load().gen + 1,
+		core: zapcore.NewCore(enc, ws, coreLevel(s.Level, sw.builder.level)),
+	})
+	return nil
+}
+
+// switchableCore writes through the current core of a consoleSwitch. Fields
+// added with With are kept so they can be re-applied after a swap.
+type switchableCore struct {
+	sw     *consoleSwitch
+	fields []zapcore.Field
+	cache  atomic.Pointer[switchState] // current core with fields applied
+}
+
+func (c *switchableCore) core() zapcore.Core {
+	st := c.sw.current.Load()
+	if len(c.fields) == 0 {
+		return st.core
+	}
+	if cached := c.cache.Load(); cached != nil && cached.gen == st.gen {
+		return cached.core
+	}
+	derived := &switchState{gen: st.gen, core: st.core.With(c.fields)}
+	c.cache.Store(derived)
+	return derived.core
+}
+
+func (c *switchableCore) Enabled(lvl zapcore.Level) bool {
+	return c.core().Enabled(lvl)
+}
+
+func (c *switchableCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	return &switchableCore{sw: c.sw, fields: all}
+}
+
+func (c *switchableCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *switchableCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.core().Write(ent, fields)
+}
+
+func (c *switchableCore) Sync() error {
+	return c.core().Sync()
+}
+
+// SetFormat switches the stdout/stderr outputs of the global logger to
+// format at runtime, e.g. from console to json for a debugging session.
+// File and network outputs keep their format. format must be json,
+// json-pretty, console, gelf or a name passed to RegisterEncoder.
+func SetFormat(format string) error {
+	if _, ok := lookupEncoder(format); !ok {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	for _, sw := range currentState().consoles {
+		if err := sw.setFormat(format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package zlog
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSetFormatSwitchesConsoleOnly(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Output = "both"
+	cfg.Format = "console"
+	useGlobal(t, cfg)
+
+	buf, restore := CaptureToBuffer()
+	defer restore()
+	child := Logger().With(String("component", "worker"))
+	Info("before")
+	if err := SetFormat("json"); err != nil {
+		t.Fatal(err)
+	}
+	Info("after")
+	child.Info("derived") // With fields survive the switch
+	restore()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("captured %d lines, want 3:\n%s", len(lines), buf)
+	}
+	if strings.HasPrefix(lines[0], "{") {
+		t.Errorf("line before SetFormat is JSON: %s", lines[0])
+	}
+	for _, line := range lines[1:] {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Errorf("line after SetFormat is not JSON: %s", line)
+		}
+	}
+	if !strings.Contains(lines[2], `"component":"worker"`) {
+		t.Errorf("derived logger lost its fields: %s", lines[2])
+	}
+
+	// The file keeps its format
+	Sync()
+	data, err := os.ReadFile(cfg.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.HasPrefix(line, "{") {
+			t.Errorf("file line switched to JSON: %s", line)
+		}
+	}
+}
+
+func TestSetFormatRejectsUnknownFormat(t *testing.T) {
+	useGlobal(t, testConfig(t))
+	if err := SetFormat("nope"); err == nil {
+		t.Error("SetFormat accepted an unknown format")
+	}
+}
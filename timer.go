@@ -0,0 +1,18 @@
+package zlog
+
+import "time"
+
+// Timer starts timing and returns a function that logs msg at info level with
+// the elapsed time appended as an "elapsed" Duration field:
+//
+//	done := zlog.Timer()
+//	...
+//	done("import finished", zlog.Int("rows", n))
+func Timer() func(msg string, fields ...Field) {
+	start := time.Now()
+	return func(msg string, fields ...Field) {
+		fields = append(fields[:len(fields):len(fields)], Duration("elapsed", time.Since(start)))
+		executeHooks(InfoLevel, msg, fields)
+		Logger().Info(msg, fields...)
+	}
+}
@@ -0,0 +1,13 @@
+package zlog
+
+import "testing"
+
+func TestTimerKeepsCallerSlice(t *testing.T) {
+	useGlobal(t, testConfig(t))
+	fields := make([]Field, 1, 4)
+	fields[0] = String("k", "v")
+	Timer()("done", fields...)
+	if spare := fields[:2][1]; spare != (Field{}) {
+		t.Errorf("Timer wrote %v into the caller's slice", spare)
+	}
+}
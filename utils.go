@@ -1,30 +1,138 @@
 package zlog
 
-// toZapFields converts zlog.Field to zap.Field
-// func toZapFields(fields []Field) []zap.Field {
-// 	if len(fields) == 0 {
-// 		return nil
-// 	}
-// 	zfs := make([]zap.Field, 0, len(fields))
-// 	for _, f := range fields {
-// 		switch f.typ {
-// 		case fieldString:
-// 			zfs = append(zfs, zap.String(f.key, f.value.(string)))
-// 		case fieldInt:
-// 			zfs = append(zfs, zap.Int(f.key, f.value.(int)))
-// 		case fieldInt64:
-// 			zfs = append(zfs, zap.Int64(f.key, f.value.(int64)))
-// 		case fieldBool:
-// 			zfs = append(zfs, zap.Bool(f.key, f.value.(bool)))
-// 		case fieldFloat64:
-// 			zfs = append(zfs, zap.Float64(f.key, f.value.(float64)))
-// 		case fieldDuration:
-// 			zfs = append(zfs, zap.Duration(f.key, f.value.(time.Duration)))
-// 		case fieldTime:
-// 			zfs = append(zfs, zap.Time(f.key, f.value.(time.Time)))
-// 		default:
-// 			zfs = append(zfs, zap.Any(f.key, f.value))
-// 		}
-// 	}
-// 	return zfs
-// }
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// sprintln formats args like fmt.Sprintln, without the trailing newline
+func sprintln(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+var (
+	hostnameOnce   sync.Once
+	cachedHostname string
+)
+
+// hostname returns os.Hostname, looked up once per process. It returns
+// "unknown" and warns on stderr if the lookup fails.
+func hostname() string {
+	hostnameOnce.Do(func() {
+		name, err := os.Hostname()
+		if err != nil || name == "" {
+			fmt.Fprintf(os.Stderr, "[zlog] failed to get hostname: %v\n", err)
+			name = "unknown"
+		}
+		cachedHostname = name
+	})
+	return cachedHostname
+}
+
+// maxErrorFieldsDepth bounds how deep LogFielder fields are expanded when the
+// fields of an error contain further errors
+const maxErrorFieldsDepth = 5
+
+// toZapFields normalizes fields before encoding. zap's encoders type-assert the
+// Interface of a field without checking, so a Field built by hand (or by
+// reflection) with a mismatched value would panic inside the logging call.
+// Such fields are replaced by zap.Any so the value is still logged.
+// It also resolves Lazy fields, which only happens once an entry is written,
+// and adds the fields of errors implementing LogFielder after the error.
+func toZapFields(_ *zapcore.Entry, fields []Field) []Field {
+	return normalizeFields(fields, 0)
+}
+
+func normalizeFields(fields []Field, depth int) []Field {
+	var out []Field
+	for i, f := range fields {
+		matches := fieldValueMatches(f)
+		var extra []Field
+		if matches && f.Type == zapcore.ErrorType && depth < maxErrorFieldsDepth {
+			extra = errorLogFields(f.Interface.(error), depth)
+		}
+		if matches && len(extra) == 0 {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields)+len(extra))
+			copy(out, fields[:i])
+		}
+		switch {
+		case matches:
+			out = append(out, f)
+		case isLazy(f):
+			out = append(out, zap.Any(f.Key, f.Interface.(lazyValue)()))
+		default:
+			out = append(out, zap.Any(f.Key, f.Interface))
+		}
+		out = append(out, extra...)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+func isLazy(f Field) bool {
+	_, ok := f.Interface.(lazyValue)
+	return ok
+}
+
+// errorLogFields returns the normalized fields of the first error in err's
+// chain implementing LogFielder. A LogFields method that panics, e.g. on a
+// nil receiver, contributes no fields.
+func errorLogFields(err error, depth int) (fields []Field) {
+	var lf LogFielder
+	if !errors.As(err, &lf) {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			fields = nil
+		}
+	}()
+	return normalizeFields(lf.LogFields(), depth+1)
+}
+
+// fieldValueMatches reports whether f.Interface holds what its Type requires
+func fieldValueMatches(f Field) bool {
+	var ok bool
+	switch f.Type {
+	case zapcore.ArrayMarshalerType:
+		_, ok = f.Interface.(zapcore.ArrayMarshaler)
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+		_, ok = f.Interface.(zapcore.ObjectMarshaler)
+	case zapcore.BinaryType, zapcore.ByteStringType:
+		_, ok = f.Interface.([]byte)
+	case zapcore.Complex128Type:
+		_, ok = f.Interface.(complex128)
+	case zapcore.Complex64Type:
+		_, ok = f.Interface.(complex64)
+	case zapcore.TimeFullType:
+		_, ok = f.Interface.(time.Time)
+	case zapcore.TimeType:
+		_, ok = f.Interface.(*time.Location)
+		ok = ok || f.Interface == nil
+	case zapcore.StringerType:
+		_, ok = f.Interface.(fmt.Stringer)
+	case zapcore.ErrorType:
+		_, ok = f.Interface.(error)
+	case zapcore.SkipType:
+		_, lazy := f.Interface.(lazyValue)
+		ok = !lazy
+	default:
+		ok = true
+	}
+	return ok
+}
@@ -0,0 +1,61 @@
+package zlog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestToZapFieldsMistypedInterface(t *testing.T) {
+	tests := []struct {
+		name  string
+		field Field
+	}{
+		{"array", Field{Key: "k", Type: zapcore.ArrayMarshalerType, Interface: 42}},
+		{"object", Field{Key: "k", Type: zapcore.ObjectMarshalerType, Interface: 42}},
+		{"inline", Field{Key: "k", Type: zapcore.InlineMarshalerType, Interface: 42}},
+		{"binary", Field{Key: "k", Type: zapcore.BinaryType, Interface: 42}},
+		{"bytestring", Field{Key: "k", Type: zapcore.ByteStringType, Interface: "s"}},
+		{"complex128", Field{Key: "k", Type: zapcore.Complex128Type, Interface: 42}},
+		{"complex64", Field{Key: "k", Type: zapcore.Complex64Type, Interface: complex128(1)}},
+		{"timefull", Field{Key: "k", Type: zapcore.TimeFullType, Interface: 42}},
+		{"time", Field{Key: "k", Type: zapcore.TimeType, Integer: 1, Interface: 42}},
+		{"stringer", Field{Key: "k", Type: zapcore.StringerType, Interface: 42}},
+		{"error", Field{Key: "k", Type: zapcore.ErrorType, Interface: 42}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fieldValueMatches(tt.field) {
+				t.Fatalf("fieldValueMatches(%v) = true, want false", tt.field.Type)
+			}
+			got := toZapFields(nil, []Field{tt.field})
+			if len(got) != 1 {
+				t.Fatalf("got %d fields, want 1", len(got))
+			}
+			want := zap.Any("k", tt.field.Interface)
+			if !got[0].Equals(want) {
+				t.Errorf("got %#v, want %#v", got[0], want)
+			}
+			// encoding must not panic once normalized
+			got[0].AddTo(zapcore.NewMapObjectEncoder())
+		})
+	}
+}
+
+func TestToZapFieldsMatching(t *testing.T) {
+	fields := []Field{
+		zap.String("s", "v"),
+		zap.Binary("b", []byte{1}),
+		zap.Time("t", time.Unix(0, 0)),
+		zap.Stringer("st", time.Second),
+		zap.Error(errors.New("boom")),
+		zap.Skip(),
+	}
+	got := toZapFields(nil, fields)
+	if &got[0] != &fields[0] {
+		t.Fatal("matching fields were copied")
+	}
+}
@@ -0,0 +1,333 @@
+package zlog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// fileFailureThreshold is the number of consecutive write failures after which
+// a fallbackWriteSyncer gives up on the file
+const fileFailureThreshold = 3
+
+// templateTokenPattern matches a {token} in FilenameTemplate
+var templateTokenPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// validateFilenameTemplate returns an error if tmpl uses an unknown token
+func validateFilenameTemplate(tmpl string) error {
+	for _, tok := range templateTokenPattern.FindAllString(tmpl, -1) {
+		switch tok {
+		case "{date}", "{hour}", "{pid}", "{hostname}":
+		default:
+			return fmt.Errorf("unknown token %s in FilenameTemplate %q", tok, tmpl)
+		}
+	}
+	rest := templateTokenPattern.ReplaceAllString(tmpl, "")
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("unbalanced braces in FilenameTemplate %q", tmpl)
+	}
+	return nil
+}
+
+// newLumberjack builds the size-rotating writer for filename
+func newLumberjack(filename string, cfg LoggerConfig) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    cfg.MaxSize,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge,
+		Compress:   cfg.Compress,
+	}
+}
+
+// newFileWriter builds the writer behind the file output
+func newFileWriter(cfg LoggerConfig) (io.WriteCloser, error) {
+	if cfg.FilenameTemplate == "" {
+		return newRotatingFile(cfg.FilePath, cfg), nil
+	}
+	if err := validateFilenameTemplate(cfg.FilenameTemplate); err != nil {
+		return nil, err
+	}
+	loc, err := rotateLocation(cfg.RotateLocation)
+	if err != nil {
+		return nil, err
+	}
+	static := strings.NewReplacer(
+		"{pid}", strconv.Itoa(os.Getpid()),
+		"{hostname}", hostname(),
+	)
+	w := &templateWriter{
+		dir:  filepath.Dir(cfg.FilePath),
+		tmpl: static.Replace(cfg.FilenameTemplate),
+		cfg:  cfg,
+		loc:  loc,
+		now:  func() time.Time { return time.Now().In(loc) },
+	}
+	w.pattern, w.groups = templateFilePattern(w.tmpl)
+	return w, nil
+}
+
+// templateFilePattern returns a pattern matching the base names of the files
+// rendered from tmpl, their size-rotated backups included, and the token of
+// each of its groups but the last two (the backup part and the compression
+// suffix). It returns nil when tmpl has no {date} in its file name, as the
+// periods cannot be ordered then, or renders files in more than one directory.
+func templateFilePattern(tmpl string) (*regexp.Regexp, []string) {
+	if strings.Contains(filepath.Dir(tmpl), "{") {
+		return nil, nil
+	}
+	base := filepath.Base(tmpl)
+	ext := filepath.Ext(base)
+	if strings.Contains(ext, "{") {
+		ext = ""
+	}
+	stem := strings.TrimSuffix(base, ext)
+
+	var groups []string
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range templateTokenPattern.FindAllStringIndex(stem, -1) {
+		b.WriteString(regexp.QuoteMeta(stem[last:loc[0]]))
+		switch tok := stem[loc[0]:loc[1]]; tok {
+		case "{date}":
+			b.WriteString(`(\d{4}-\d{2}-\d{2})`)
+			groups = append(groups, tok)
+		case "{hour}":
+			b.WriteString(`(\d{2})`)
+			groups = append(groups, tok)
+		}
+		last = loc[1]
+	}
+	if !strings.Contains(stem, "{date}") {
+		return nil, nil
+	}
+	b.WriteString(regexp.QuoteMeta(stem[last:]))
+	b.WriteString("(.*)" + regexp.QuoteMeta(ext) + "(" + regexp.QuoteMeta(compressSuffix) + ")?$")
+	return regexp.MustCompile(b.String()), groups
+}
+
+// rotateLocation loads the time zone used for {date} and {hour}; empty means local time
+func rotateLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RotateLocation %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// templateWriter writes to a file named by rendering FilenameTemplate with the
+// current time. When the rendered name changes, e.g. {date} rolls over at
+// midnight, the current file is closed and the new one opened, giving
+// time-based rotation on top of the size-based rotation.
+//
+// MaxBackups and MaxAge also apply to the files of earlier periods: every
+// time a new file is opened, the files (and their backups) of periods beyond
+// the MaxBackups most recent ones, or that started more than MaxAge ago, are
+// removed. This needs {date} in the file name itself; other templates get no
+// retention across periods.
+type templateWriter struct {
+	mu      sync.Mutex
+	dir     string
+	tmpl    string // {pid} and {hostname} already substituted
+	cfg     LoggerConfig
+	loc     *time.Location
+	now     func() time.Time
+	current string
+	file    io.WriteCloser
+
+	pattern *regexp.Regexp // see templateFilePattern; nil without retention
+	groups  []string
+	millMu  sync.Mutex // serializes cleanups of earlier periods
+}
+
+// filename renders the template for t
+func (w *templateWriter) filename(t time.Time) string {
+	name := strings.NewReplacer(
+		"{date}", t.Format("2006-01-02"),
+		"{hour}", t.Format("15"),
+	).Replace(w.tmpl)
+	return filepath.Join(w.dir, name)
+}
+
+func (w *templateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	name := w.filename(w.now())
+	if name != w.current {
+		if w.file != nil {
+			_ = w.file.Close()
+		}
+		w.file = newRotatingFile(name, w.cfg)
+		w.current = name
+		if w.pattern != nil {
+			go w.mill(name)
+		}
+	}
+	return w.file.Write(p)
+}
+
+// periodFile is a file of an earlier period of a templateWriter
+type periodFile struct {
+	path  string
+	start time.Time // of the period, parsed from the name
+}
+
+// mill removes the files of the periods before current's that MaxBackups and
+// MaxAge no longer keep
+func (w *templateWriter) mill(current string) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+	if w.cfg.MaxBackups <= 0 && w.cfg.MaxAge <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(current)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	currentStart, ok := w.periodStart(filepath.Base(current))
+	if !ok {
+		return
+	}
+	var files []periodFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		start, ok := w.periodStart(e.Name())
+		if ok && start.Before(currentStart) {
+			files = append(files, periodFile{path: filepath.Join(dir, e.Name()), start: start})
+		}
+	}
+	// Newest period first; the files of one period stay together
+	sort.Slice(files, func(i, j int) bool { return files[i].start.After(files[j].start) })
+
+	cutoff := w.now().Add(-time.Duration(w.cfg.MaxAge) * 24 * time.Hour)
+	periods := 0
+	for i, f := range files {
+		if i == 0 || !f.start.Equal(files[i-1].start) {
+			periods++
+		}
+		if (w.cfg.MaxBackups > 0 && periods > w.cfg.MaxBackups) || (w.cfg.MaxAge > 0 && f.start.Before(cutoff)) {
+			os.Remove(f.path)
+		}
+	}
+}
+
+// periodStart returns the start of the period of a file named name, if it
+// is a file of this writer or one of its backups
+func (w *templateWriter) periodStart(name string) (time.Time, bool) {
+	m := w.pattern.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	if backup := m[len(m)-2]; backup != "" && !w.isBackupSuffix(backup) {
+		return time.Time{}, false
+	}
+	date, hour := "0000-01-01", "00"
+	for i, tok := range w.groups {
+		switch tok {
+		case "{date}":
+			date = m[i+1]
+		case "{hour}":
+			hour = m[i+1]
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02 15", date+" "+hour, w.loc)
+	return t, err == nil
+}
+
+// isBackupSuffix reports whether s is what size rotation appends to a file
+// name, so that other files sharing the template's prefix are left alone
+func (w *templateWriter) isBackupSuffix(s string) bool {
+	sep, layout := w.cfg.BackupSeparator, w.cfg.BackupTimeFormat
+	if sep == "" {
+		sep = defaultBackupSeparator
+	}
+	if layout == "" {
+		layout = defaultBackupTimeFormat
+	}
+	if !strings.HasPrefix(s, sep) {
+		return false
+	}
+	_, err := time.Parse(layout, strings.TrimPrefix(s, sep))
+	return err == nil
+}
+
+// Rotate rotates the current file, if it has been opened
+func (w *templateWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if r, ok := w.file.(rotator); ok {
+		return r.Rotate()
+	}
+	return nil
+}
+
+func (w *templateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// fallbackWriteSyncer writes to ws until it fails fileFailureThreshold times in
+// a row, then reports the problem once and sends everything to fallback, so
+// logs keep flowing when the disk fills up or the log directory disappears.
+type fallbackWriteSyncer struct {
+	ws       zapcore.WriteSyncer
+	fallback zapcore.WriteSyncer
+	failures atomic.Int32
+	switched atomic.Bool
+}
+
+func newFallbackWriteSyncer(ws, fallback zapcore.WriteSyncer) *fallbackWriteSyncer {
+	return &fallbackWriteSyncer{ws: ws, fallback: fallback}
+}
+
+func (w *fallbackWriteSyncer) Write(p []byte) (int, error) {
+	if w.switched.Load() {
+		return w.fallback.Write(p)
+	}
+	n, err := w.ws.Write(p)
+	if err == nil {
+		w.failures.Store(0)
+		return n, nil
+	}
+	if w.failures.Add(1) < fileFailureThreshold {
+		return n, err
+	}
+	if w.switched.CompareAndSwap(false, true) {
+		fmt.Fprintf(os.Stderr, "[zlog] file output failed %d times in a row (last error: %v), falling back to stderr\n",
+			fileFailureThreshold, err)
+	}
+	return w.fallback.Write(p)
+}
+
+func (w *fallbackWriteSyncer) Sync() error {
+	if w.switched.Load() {
+		return w.fallback.Sync()
+	}
+	return w.ws.Sync()
+}
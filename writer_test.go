@@ -0,0 +1,91 @@
+package zlog
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTemplateWriterRetention(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		maxAge     int
+		want       []string
+	}{
+		{"max backups", 2, 0, []string{
+			"app-2026-10-12.log",
+			"app-2026-10-13-worker.log",
+			"app-2026-10-13.log",
+			"app-2026-10-15.log",
+			"other.log",
+		}},
+		{"max age", 0, 3, []string{
+			"app-2026-10-13-worker.log",
+			"app-2026-10-13.log",
+			"app-2026-10-15.log",
+			"other.log",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{
+				"app-2026-10-10.log",
+				"app-2026-10-11.log",
+				"app-2026-10-11-2026-10-11T12-00-00.000.log.gz",
+				"app-2026-10-12.log",
+				"app-2026-10-13.log",
+				"app-2026-10-13-worker.log", // not a backup of app-2026-10-13.log
+				"app-2026-10-15.log",
+				"other.log",
+			} {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg := DefaultConfig()
+			cfg.FilePath = filepath.Join(dir, "app.log")
+			cfg.FilenameTemplate = "app-{date}.log"
+			cfg.MaxBackups = tt.maxBackups
+			cfg.MaxAge = tt.maxAge
+			writer, err := newFileWriter(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := writer.(*templateWriter)
+			now := time.Date(2026, 10, 15, 10, 0, 0, 0, w.loc)
+			w.now = func() time.Time { return now }
+			w.mill(w.filename(now))
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Name())
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateFilePatternNeedsDate(t *testing.T) {
+	for _, tmpl := range []string{"app-{hour}.log", "{date}/app.log", "app.log"} {
+		if p, _ := templateFilePattern(tmpl); p != nil {
+			t.Errorf("templateFilePattern(%q) = %v, want no retention", tmpl, p)
+		}
+	}
+}
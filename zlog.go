@@ -31,26 +31,32 @@ func Fatal(msg string, fields ...Field) {
 
 // ========== Key-Value Logging (Easy to Use, Suitable for Rapid Development) ==========
 func Debugw(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
 	executeHooks(DebugLevel, msg, nil)
 	Sugar().Debugw(msg, keysAndValues...)
 }
 func Infow(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
 	executeHooks(InfoLevel, msg, nil)
 	Sugar().Infow(msg, keysAndValues...)
 }
 func Warnw(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
 	executeHooks(WarnLevel, msg, nil)
 	Sugar().Warnw(msg, keysAndValues...)
 }
 func Errorw(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
 	executeHooks(ErrorLevel, msg, nil)
 	Sugar().Errorw(msg, keysAndValues...)
 }
 func Panicw(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
 	executeHooks(PanicLevel, msg, nil)
 	Sugar().Panicw(msg, keysAndValues...)
 }
 func Fatalw(msg string, keysAndValues ...interface{}) {
+	checkKeysAndValues(keysAndValues)
 	executeHooks(FatalLevel, msg, nil)
 	Sugar().Fatalw(msg, keysAndValues...)
 }
@@ -80,3 +86,42 @@ func Fatalf(format string, args ...interface{}) {
 	executeHooks(FatalLevel, fmt.Sprintf(format, args...), nil)
 	Sugar().Fatalf(format, args...)
 }
+
+// ========== Println-style Logging (log.Println Compatible) ==========
+// Arguments are joined with spaces, like fmt.Sprintln without the trailing newline
+func Debugln(args ...interface{}) {
+	executeHooks(DebugLevel, sprintln(args...), nil)
+	Sugar().Debugln(args...)
+}
+func Infoln(args ...interface{}) {
+	executeHooks(InfoLevel, sprintln(args...), nil)
+	Sugar().Infoln(args...)
+}
+func Warnln(args ...interface{}) {
+	executeHooks(WarnLevel, sprintln(args...), nil)
+	Sugar().Warnln(args...)
+}
+func Errorln(args ...interface{}) {
+	executeHooks(ErrorLevel, sprintln(args...), nil)
+	Sugar().Errorln(args...)
+}
+
+// ========== Conditional Logging (Replaces if-guards Around Log Calls) ==========
+// InfoIf logs at info level only when cond is true
+func InfoIf(cond bool, msg string, fields ...Field) {
+	if !cond {
+		return
+	}
+	executeHooks(InfoLevel, msg, fields)
+	Logger().Info(msg, fields...)
+}
+
+// ErrorIfErr logs at error level only when err is non-nil, appending Err(err)
+func ErrorIfErr(err error, msg string, fields ...Field) {
+	if err == nil {
+		return
+	}
+	fields = append(fields[:len(fields):len(fields)], Err(err))
+	executeHooks(ErrorLevel, msg, fields)
+	Logger().Error(msg, fields...)
+}
@@ -0,0 +1,16 @@
+package zlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorIfErrKeepsCallerSlice(t *testing.T) {
+	useGlobal(t, testConfig(t))
+	fields := make([]Field, 1, 4)
+	fields[0] = String("k", "v")
+	ErrorIfErr(errors.New("boom"), "failed", fields...)
+	if spare := fields[:2][1]; spare != (Field{}) {
+		t.Errorf("ErrorIfErr wrote %v into the caller's slice", spare)
+	}
+}
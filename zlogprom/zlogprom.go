@@ -0,0 +1,54 @@
+// Package zlogprom exports zlog metrics to Prometheus. It lives apart from
+// zlog so that programs not importing it do not compile or link the
+// Prometheus client. The zlog module still requires the client, so it does
+// appear in their module graph.
+package zlogprom
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chenzanhong/zlog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// prometheusHook counts log calls per level
+type prometheusHook struct {
+	counter *prometheus.CounterVec
+}
+
+// NewPrometheusHook returns a LogHook that counts log calls in a
+// log_messages_total{level="..."} counter registered with registerer.
+// If an identical counter is already registered, the existing one is reused,
+// so calling it more than once is safe. Any other registration error, such
+// as a different collector already using the name, is returned.
+//
+//	hook, err := zlogprom.NewPrometheusHook(prometheus.DefaultRegisterer)
+//	if err != nil {
+//		return err
+//	}
+//	zlog.RegisterLogHook(hook)
+func NewPrometheusHook(registerer prometheus.Registerer) (zlog.LogHook, error) {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "log_messages_total",
+		Help: "Total number of log messages by level.",
+	}, []string{"level"})
+
+	if err := registerer.Register(counter); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			return nil, fmt.Errorf("register log_messages_total: %w", err)
+		}
+		existing, ok := are.ExistingCollector.(*prometheus.CounterVec)
+		if !ok {
+			return nil, fmt.Errorf("register log_messages_total: existing collector is a %T, not a CounterVec", are.ExistingCollector)
+		}
+		counter = existing
+	}
+	return &prometheusHook{counter: counter}, nil
+}
+
+func (h *prometheusHook) OnLog(level zlog.Level, msg string, fields []zlog.Field) error {
+	h.counter.WithLabelValues(level.String()).Inc()
+	return nil
+}
@@ -0,0 +1,44 @@
+package zlogprom
+
+import (
+	"testing"
+
+	"github.com/chenzanhong/zlog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNewPrometheusHookReusesCounter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first, err := NewPrometheusHook(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewPrometheusHook(reg)
+	if err != nil {
+		t.Fatalf("second registration: %v", err)
+	}
+	_ = first.OnLog(zlog.InfoLevel, "a", nil)
+	_ = second.OnLog(zlog.InfoLevel, "b", nil)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || len(families[0].GetMetric()) != 1 {
+		t.Fatalf("got %v, want one info counter", families)
+	}
+	if got := families[0].GetMetric()[0].GetCounter().GetValue(); got != 2 {
+		t.Errorf("info count = %v, want 2", got)
+	}
+}
+
+func TestNewPrometheusHookConflict(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "log_messages_total",
+		Help: "Something else.",
+	}))
+	if _, err := NewPrometheusHook(reg); err == nil {
+		t.Fatal("expected an error for a conflicting collector")
+	}
+}
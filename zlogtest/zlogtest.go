@@ -0,0 +1,141 @@
+// Package zlogtest records the entries of a zlog logger in memory so tests
+// can assert on what was logged.
+package zlogtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chenzanhong/zlog"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// CapturedEntry is an entry recorded by an observer logger
+type CapturedEntry struct {
+	Level   zlog.Level
+	Time    time.Time
+	Message string
+	Caller  string // file:line, empty when caller is unavailable
+	Fields  map[string]interface{}
+}
+
+// NewObserver returns a logger that keeps every entry at or above level in
+// memory instead of writing it, and a function returning the entries logged
+// so far. Fields go through the same normalization as the real logger.
+//
+//	log, entries := zlogtest.NewObserver(zlog.DebugLevel)
+//	doWork(log)
+//	zlogtest.AssertLogged(t, entries(), zlog.InfoLevel, "done", map[string]interface{}{"count": 3})
+func NewObserver(level zlog.Level) (*zlog.ZLogger, func() []CapturedEntry) {
+	// zlog filters by level, so the observer itself records everything
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	entries := func() []CapturedEntry {
+		all := logs.All()
+		captured := make([]CapturedEntry, 0, len(all))
+		for _, e := range all {
+			c := CapturedEntry{
+				// zlog levels are named like zap's
+				Level:   zlog.Level(e.Level.String()),
+				Time:    e.Time,
+				Message: e.Message,
+				Fields:  e.ContextMap(),
+			}
+			if e.Caller.Defined {
+				c.Caller = e.Caller.TrimmedPath()
+			}
+			captured = append(captured, c)
+		}
+		return captured
+	}
+	return zlog.NewWithCore(core, level), entries
+}
+
+// AssertLogged fails t unless entries contains an entry with the given level
+// and message whose fields include every key in wantFields with an equal
+// value (numbers compare by value, so 3 matches an Int64 field). On failure
+// it reports the closest entry and what differed.
+func AssertLogged(t testing.TB, entries []CapturedEntry, level zlog.Level, msg string, wantFields map[string]interface{}) {
+	t.Helper()
+
+	var closest *CapturedEntry
+	var closestDiffs []string
+	for i := range entries {
+		diffs := entryDiffs(&entries[i], level, msg, wantFields)
+		if len(diffs) == 0 {
+			return
+		}
+		if closest == nil || len(diffs) < len(closestDiffs) {
+			closest, closestDiffs = &entries[i], diffs
+		}
+	}
+
+	if closest == nil {
+		t.Errorf("zlog: no %s entry %q logged: no entries captured", level, msg)
+		return
+	}
+	t.Errorf("zlog: no %s entry %q logged with fields %v (%d entries captured)\nclosest: %s %q %v\n  %s",
+		level, msg, wantFields, len(entries), closest.Level, closest.Message, closest.Fields,
+		strings.Join(closestDiffs, "\n  "))
+}
+
+// entryDiffs describes how e differs from the wanted entry
+func entryDiffs(e *CapturedEntry, level zlog.Level, msg string, wantFields map[string]interface{}) []string {
+	var diffs []string
+	if e.Level != level {
+		diffs = append(diffs, fmt.Sprintf("level: got %s, want %s", e.Level, level))
+	}
+	if e.Message != msg {
+		diffs = append(diffs, fmt.Sprintf("message: got %q, want %q", e.Message, msg))
+	}
+
+	keys := make([]string, 0, len(wantFields))
+	for k := range wantFields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		got, ok := e.Fields[k]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("field %q: missing", k))
+		case !capturedValueEqual(got, wantFields[k]):
+			diffs = append(diffs, fmt.Sprintf("field %q: got %v (%T), want %v (%T)", k, got, got, wantFields[k], wantFields[k]))
+		}
+	}
+	return diffs
+}
+
+// capturedValueEqual compares a captured field value with an expected one.
+// Captured integers are widened by zap, so numbers are compared by value.
+func capturedValueEqual(got, want interface{}) bool {
+	if reflect.DeepEqual(got, want) {
+		return true
+	}
+	if d, ok := want.(time.Duration); ok {
+		if gd, ok := got.(time.Duration); ok {
+			return gd == d
+		}
+	}
+	g, gok := numericValue(got)
+	w, wok := numericValue(want)
+	return gok && wok && g == w
+}
+
+func numericValue(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,41 @@
+package zlogtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chenzanhong/zlog"
+)
+
+func TestNewObserver(t *testing.T) {
+	log, entries := NewObserver(zlog.InfoLevel)
+	log.Debug("dropped")
+	log.Info("done", zlog.Int("count", 3))
+	if err := log.SetLevel(zlog.DebugLevel); err != nil {
+		t.Fatal(err)
+	}
+	log.Debug("kept")
+
+	got := entries()
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2: %v", len(got), got)
+	}
+	AssertLogged(t, got, zlog.InfoLevel, "done", map[string]interface{}{"count": 3})
+	AssertLogged(t, got, zlog.DebugLevel, "kept", nil)
+	if !strings.HasPrefix(got[0].Caller, "zlogtest/zlogtest_test.go:") {
+		t.Errorf("caller = %q, want this file", got[0].Caller)
+	}
+}
+
+func TestNewObserverFatal(t *testing.T) {
+	exited := false
+	zlog.SetExitFunc(func(int) { exited = true })
+	defer zlog.SetExitFunc(nil)
+
+	log, entries := NewObserver(zlog.InfoLevel)
+	log.Fatal("stop", zlog.String("reason", "test"))
+	if !exited {
+		t.Fatal("Fatal did not call the exit func")
+	}
+	AssertLogged(t, entries(), zlog.FatalLevel, "stop", map[string]interface{}{"reason": "test"})
+}